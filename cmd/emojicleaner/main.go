@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/command/clean"
+	"emojicleaner/internal/command/download"
+	"emojicleaner/internal/command/duplicate"
+	"emojicleaner/internal/command/favorite"
+	"emojicleaner/internal/command/longest"
+	"emojicleaner/internal/command/popular"
+	"emojicleaner/internal/command/stale"
+)
+
+// 빌드할 때 -ldflags "-X main.version=..."로 덮어씀
+var version = "dev"
+
+func main() {
+	os.Exit(cli.Main(version, []*cli.Command{
+		download.Command,
+		stale.Command,
+		clean.Command,
+		duplicate.Command,
+		favorite.Command,
+		longest.Command,
+		popular.Command,
+	}, os.Args))
+}
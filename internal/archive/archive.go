@@ -0,0 +1,83 @@
+// Package archive는 download -archive가 지워질 이모지의 이미지를 받아두는 로컬 보관소의 index를 다룸
+//
+// 이미지는 내용의 sha256으로 저장해 같은 이미지는 한 번만 저장됨
+//
+//	<archive>/index.json
+//	<archive>/objects/ab/ab12...ef.png
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+const (
+	IndexJSONName = "index.json"
+	ObjectsDir    = "objects"
+)
+
+type Index struct {
+	Emojis map[string]Emoji `json:"emojis"`
+	// alias 이름 → 원본 이모지 이름. 원본이 기본 이모지인 alias도 복구할 수 있게 따로 저장
+	Aliases map[string]string `json:"aliases"`
+}
+
+type Emoji struct {
+	Name       string    `json:"name"`
+	Aliases    []string  `json:"aliases,omitempty"`
+	URL        string    `json:"url"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Path       string    `json:"path"`
+	ArchivedAt time.Time `json:"archived_at"`
+	// 마지막으로 워크스페이스에서 확인된 시각. 지워진 이모지는 더 이상 갱신되지 않음
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Emojis:  make(map[string]Emoji),
+		Aliases: make(map[string]string),
+	}
+}
+
+// index가 없으면 os.ErrNotExist를 그대로 반환함. 처음 받는 download만 빈 index로 시작함
+func Load(dir string) (*Index, error) {
+	p := filepath.Join(dir, IndexJSONName)
+	bb, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	index := NewIndex()
+	if err := json.Unmarshal(bb, index); err != nil {
+		return nil, errors.Wrap(err, p)
+	}
+	return index, nil
+}
+
+func (i *Index) Save(dir string) error {
+	return dataset.SaveJSON(filepath.Join(dir, IndexJSONName), i)
+}
+
+// 받아둔 이미지 파일이 남아있다면 그 경로를 반환
+func (i *Index) ImagePath(dir, name string) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	e, ok := i.Emojis[mrkdwn.NormalizeName(name)]
+	if !ok {
+		return "", false
+	}
+	p := filepath.Join(dir, filepath.FromSlash(e.Path))
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	return p, true
+}
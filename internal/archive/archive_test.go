@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	index := NewIndex()
+	index.Emojis["party"] = Emoji{Name: "party", Path: "objects/ab/ab12.png"}
+	require.NoError(t, index.Save(dir))
+
+	got, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, index.Emojis, got.Emojis)
+	// 저장된 index에 없던 맵도 비어있는 채로 만들어둠
+	assert.NotNil(t, got.Aliases)
+}
+
+func TestIndex_ImagePath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "objects", "ab"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "objects", "ab", "ab12.png"), []byte("\x89PNG party"), 0644))
+	index := NewIndex()
+	index.Emojis["party"] = Emoji{Name: "party", Path: "objects/ab/ab12.png"}
+	index.Emojis["gone"] = Emoji{Name: "gone", Path: "objects/cd/cd34.png"}
+
+	p, ok := index.ImagePath(dir, "Party")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "objects", "ab", "ab12.png"), p)
+
+	// 이미지 파일이 지워졌거나 받은 적 없는 이모지
+	_, ok = index.ImagePath(dir, "gone")
+	assert.False(t, ok)
+	_, ok = index.ImagePath(dir, "tada")
+	assert.False(t, ok)
+	_, ok = (*Index)(nil).ImagePath(dir, "party")
+	assert.False(t, ok)
+}
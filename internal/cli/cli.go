@@ -0,0 +1,203 @@
+// Package cli는 emojicleaner 하나의 바이너리로 여러 커맨드를 실행함
+//
+//	emojicleaner [global flags] <command> [flags]
+//
+// 전역 플래그는 커맨드 뒤에 써도 되기에 예전처럼 "emojicleaner stale -dataset data-2025"도 동작함
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const name = "emojicleaner"
+
+// 종료 코드
+const (
+	ExitOK = 0
+	// 실행 중에 실패함
+	ExitError = 1
+	// 커맨드나 플래그를 잘못 씀
+	ExitUsage = 2
+	// ctrl+c로 중단함. 셸이 SIGINT로 끝난 프로세스에 주는 값과 같음
+	ExitInterrupted = 130
+)
+
+// 커맨드 하나. Setup은 fs에 커맨드 전용 플래그를 정의하고 플래그를 읽은 뒤 실행할 함수를 반환함
+type Command struct {
+	Name  string
+	Short string
+	Setup func(fs *flag.FlagSet, g *Globals) func(ctx context.Context) error
+}
+
+// 플래그 값이 잘못된 경우처럼 사용법을 다시 보여줘야 하는 에러
+type usageError struct {
+	error
+}
+
+func UsageErrorf(format string, args ...interface{}) error {
+	return usageError{errors.Errorf(format, args...)}
+}
+
+// args[0]은 프로그램 이름. 종료 코드를 반환함
+func Main(version string, commands []*Command, args []string) int {
+	return run(version, commands, args, os.Stdout, os.Stderr)
+}
+
+func run(version string, commands []*Command, args []string, stdout, stderr io.Writer) int {
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	g := newGlobals(version)
+
+	top := flag.NewFlagSet(name, flag.ContinueOnError)
+	top.SetOutput(stderr)
+	g.register(top)
+	top.Usage = func() {
+		printUsage(stderr, top, commands)
+	}
+	if err := top.Parse(args[1:]); err != nil {
+		return parseExitCode(err)
+	}
+	rest := top.Args()
+	if len(rest) == 0 {
+		printUsage(stderr, top, commands)
+		return ExitUsage
+	}
+
+	switch rest[0] {
+	case "help":
+		if len(rest) == 1 {
+			printUsage(stdout, top, commands)
+			return ExitOK
+		}
+		cmd := find(commands, rest[1])
+		if cmd == nil {
+			fmt.Fprintf(stderr, "unknown command '%s'\n\n", rest[1])
+			printUsage(stderr, top, commands)
+			return ExitUsage
+		}
+		fs, _ := newCommandFlagSet(cmd, g, stdout)
+		fs.Usage()
+		return ExitOK
+	case "version":
+		fmt.Fprintln(stdout, version)
+		return ExitOK
+	}
+
+	cmd := find(commands, rest[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command '%s'\n\n", rest[0])
+		printUsage(stderr, top, commands)
+		return ExitUsage
+	}
+	fs, runCommand := newCommandFlagSet(cmd, g, stderr)
+	if err := fs.Parse(rest[1:]); err != nil {
+		return parseExitCode(err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return ExitUsage
+	}
+	if err := g.apply(); err != nil {
+		fmt.Fprintf(stderr, "%s\n\n", err)
+		fs.Usage()
+		return ExitUsage
+	}
+
+	// ctrl+c로 중단하면 진행 중인 호출을 멈추고 커맨드가 정리할 기회를 줌
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := runCommand(ctx)
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "%s\n\n", err)
+		fs.Usage()
+		return ExitUsage
+	case errors.Is(err, context.Canceled):
+		log.Warn("interrupted")
+		return ExitInterrupted
+	default:
+		log.Error(err)
+		return ExitError
+	}
+}
+
+// -h는 성공으로, 나머지 플래그 에러는 flag가 사용법과 함께 이미 출력했음
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	return ExitUsage
+}
+
+func find(commands []*Command, name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func newCommandFlagSet(cmd *Command, g *Globals, w io.Writer) (*flag.FlagSet, func(ctx context.Context) error) {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(w)
+	run := cmd.Setup(fs, g)
+	own := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		own[f.Name] = true
+	})
+	g.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(w, "Usage: %s %s [flags]\n\n%s\n", name, cmd.Name, cmd.Short)
+		if len(own) > 0 {
+			fmt.Fprintf(w, "\nFlags:\n")
+			printFlags(w, fs, func(name string) bool { return own[name] })
+		}
+		fmt.Fprintf(w, "\nGlobal flags:\n")
+		printFlags(w, fs, func(name string) bool { return !own[name] })
+	}
+	return fs, run
+}
+
+func printUsage(w io.Writer, top *flag.FlagSet, commands []*Command) {
+	fmt.Fprintf(w, "Usage: %s [global flags] <command> [flags]\n\nCommands:\n", name)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.Name, cmd.Short)
+	}
+	fmt.Fprintf(w, "  %-10s %s\n", "help", "show help of a command")
+	fmt.Fprintf(w, "  %-10s %s\n", "version", "print the version")
+	fmt.Fprintf(w, "\nGlobal flags:\n")
+	printFlags(w, top, func(string) bool { return true })
+	fmt.Fprintf(w, "\nRun '%s help <command>' for flags of the command.\n", name)
+}
+
+// fs 중 골라낸 플래그만 flag.PrintDefaults 형식으로 출력
+func printFlags(w io.Writer, fs *flag.FlagSet, include func(name string) bool) {
+	picked := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	picked.SetOutput(w)
+	fs.VisitAll(func(f *flag.Flag) {
+		if include(f.Name) {
+			picked.Var(f.Value, f.Name, f.Usage)
+			// Var는 지금 값을 기본값으로 보기에 원래 기본값을 되돌려 놓음
+			picked.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+	picked.PrintDefaults()
+}
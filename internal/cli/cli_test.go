@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_run(t *testing.T) {
+	var got struct {
+		dataset string
+		limit   int
+	}
+	echo := &Command{
+		Name:  "echo",
+		Short: "remember flags",
+		Setup: func(fs *flag.FlagSet, g *Globals) func(ctx context.Context) error {
+			limit := fs.Int("limit", 3, "limit")
+			return func(ctx context.Context) error {
+				if *limit < 0 {
+					return UsageErrorf("-limit must be positive: %d", *limit)
+				}
+				got.dataset, got.limit = g.Dataset, *limit
+				return nil
+			}
+		},
+	}
+	fail := &Command{
+		Name:  "fail",
+		Short: "always fail",
+		Setup: func(fs *flag.FlagSet, g *Globals) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				return errors.New("failed")
+			}
+		},
+	}
+	exec := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := run("v1.0.0", []*Command{fail, echo}, append([]string{name}, args...), &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	// 전역 플래그는 커맨드 앞뒤 어디에 써도 됨
+	code, _ := exec("-dataset", "a", "echo", "-limit", "5", "-output-dir", outDir)
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "a", got.dataset)
+	assert.Equal(t, 5, got.limit)
+	assert.DirExists(t, outDir)
+
+	code, _ = exec("-dataset", "a", "echo", "-dataset", "b", "-output-dir", outDir)
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "b", got.dataset)
+	assert.Equal(t, 3, got.limit)
+
+	code, out := exec("help", "echo")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "Usage: emojicleaner echo [flags]")
+	assert.Contains(t, out, "-limit")
+
+	code, out = exec("version")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "v1.0.0\n", out)
+
+	code, _ = exec("echo", "-h")
+	assert.Equal(t, ExitOK, code)
+
+	code, out = exec("fail", "-output-dir", outDir)
+	assert.Equal(t, ExitError, code)
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"help", "unknown"},
+		{"echo", "-unknown"},
+		{"echo", "extra"},
+		{"echo", "-limit", "-1", "-output-dir", outDir},
+		{"echo", "-log-level", "loud"},
+		{"echo", "-format", "xml"},
+	} {
+		code, out = exec(args...)
+		assert.Equal(t, ExitUsage, code, args)
+		assert.Contains(t, out, "Usage: emojicleaner", args)
+	}
+}
+
+func TestGlobals_Save(t *testing.T) {
+	g := newGlobals("dev")
+	g.OutputDir = t.TempDir()
+	data := []map[string]int{{"tada": 1}, {"party": 2}}
+
+	require.NoError(t, g.Save("emojis.json", data))
+	bb, err := os.ReadFile(filepath.Join(g.OutputDir, "emojis.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "[\n  {\n    \"tada\": 1\n  },\n  {\n    \"party\": 2\n  }\n]", string(bb))
+
+	g.Format = FormatJSONL
+	require.NoError(t, g.Save("emojis.json", data))
+	bb, err = os.ReadFile(filepath.Join(g.OutputDir, "emojis.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"tada\":1}\n{\"party\":2}\n", string(bb))
+
+	// 배열이 아니면 한 줄
+	require.NoError(t, g.Save("count.json", map[string]int{"tada": 1}))
+	bb, err = os.ReadFile(filepath.Join(g.OutputDir, "count.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"tada\":1}\n", string(bb))
+}
+
+func TestGlobals_Load(t *testing.T) {
+	g := newGlobals("dev")
+	g.OutputDir = t.TempDir()
+	data := []map[string]int{{"tada": 1}, {"party": 2}}
+
+	for _, format := range []string{FormatJSON, FormatJSONL} {
+		g.Format = format
+		require.NoError(t, g.Save("emojis.json", data))
+		var got []map[string]int
+		require.NoError(t, g.Load("emojis.json", &got), format)
+		assert.Equal(t, data, got, format)
+
+		require.NoError(t, g.Save("count.json", map[string]int{"tada": 1}))
+		var count map[string]int
+		require.NoError(t, g.Load("count.json", &count), format)
+		assert.Equal(t, map[string]int{"tada": 1}, count, format)
+	}
+
+	// jsonl로 저장한 결과가 더 최근이라면 예전 json 결과를 읽지 않음
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(g.OutputDir, "emojis.json"), old, old))
+	g.Format = FormatJSON
+	var got []map[string]int
+	assert.Error(t, g.Load("emojis.json", &got))
+
+	assert.True(t, os.IsNotExist(g.Load("missing.json", &got)))
+}
+
+func TestGlobals_SlackToken(t *testing.T) {
+	t.Setenv("SLACK_BOT_TOKEN", "xoxp-env")
+	g := newGlobals("dev")
+	assert.Equal(t, "xoxp-env", g.SlackToken())
+
+	g.Token = "xoxp-flag"
+	assert.Equal(t, "xoxp-flag", g.SlackToken())
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/dataset"
+)
+
+// 결과 파일 형식
+const (
+	FormatJSON = "json"
+	// 배열은 원소 하나를 한 줄에 씀. jq나 grep으로 보기 좋음
+	FormatJSONL = "jsonl"
+)
+
+// 모든 커맨드가 같이 쓰는 플래그
+type Globals struct {
+	Dataset   string
+	Token     string
+	LogLevel  string
+	OutputDir string
+	Format    string
+	// 빌드할 때 -ldflags "-X main.version=..."로 넣은 값
+	Version string
+}
+
+func newGlobals(version string) *Globals {
+	return &Globals{
+		Dataset:   "data",
+		LogLevel:  log.InfoLevel.String(),
+		OutputDir: ".",
+		Format:    FormatJSON,
+		Version:   version,
+	}
+}
+
+// 커맨드 앞에서 이미 읽은 값을 기본값으로 다시 정의해 커맨드 뒤에서도 쓸 수 있게 함
+func (g *Globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.Dataset, "dataset", g.Dataset, "dataset directory made by download")
+	fs.StringVar(&g.Token, "token", g.Token, "slack token, defaults to $SLACK_BOT_TOKEN")
+	fs.StringVar(&g.LogLevel, "log-level", g.LogLevel, "log level: debug, info, warn or error")
+	fs.StringVar(&g.OutputDir, "output-dir", g.OutputDir, "directory to read and write result files in")
+	fs.StringVar(&g.Format, "format", g.Format, "format of result files: json or jsonl")
+}
+
+func (g *Globals) apply() error {
+	level, err := log.ParseLevel(g.LogLevel)
+	if err != nil {
+		return UsageErrorf("unknown -log-level '%s'", g.LogLevel)
+	}
+	log.SetLevel(level)
+	if g.Format != FormatJSON && g.Format != FormatJSONL {
+		return UsageErrorf("unknown -format '%s'", g.Format)
+	}
+	if err := os.MkdirAll(g.OutputDir, 0755); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 토큰이 필요한 커맨드만 부름. 플래그 대신 환경변수로 넘겨야 셸 기록에 남지 않음
+func (g *Globals) SlackToken() string {
+	if g.Token != "" {
+		return g.Token
+	}
+	return os.Getenv("SLACK_BOT_TOKEN")
+}
+
+// 결과 디렉토리 안의 파일 경로
+func (g *Globals) Path(name string) string {
+	return filepath.Join(g.OutputDir, name)
+}
+
+// 결과 디렉토리에 -format 형식으로 저장. jsonl이면 확장자도 .jsonl로 바꿈
+func (g *Globals) Save(name string, data interface{}) error {
+	path := g.resultPath(name, g.Format)
+	if g.Format != FormatJSONL {
+		return dataset.SaveJSON(path, data)
+	}
+	return saveJSONL(path, data)
+}
+
+// 다른 커맨드가 Save로 저장한 결과를 -format 형식으로 읽음
+// 다른 형식의 결과가 더 최근에 저장됐다면 예전 분석 결과로 작업하지 않도록 에러를 반환함
+func (g *Globals) Load(name string, v interface{}) error {
+	path := g.resultPath(name, g.Format)
+	other := FormatJSONL
+	if g.Format == FormatJSONL {
+		other = FormatJSON
+	}
+	otherPath := g.resultPath(name, other)
+	if newer(otherPath, path) {
+		return errors.Errorf("'%s' is newer than '%s', run with -format %s", otherPath, path, other)
+	}
+	return LoadFile(path, v)
+}
+
+func (g *Globals) resultPath(name, format string) string {
+	path := g.Path(name)
+	if format == FormatJSONL {
+		return strings.TrimSuffix(path, ".json") + ".jsonl"
+	}
+	return path
+}
+
+// a가 있고 b가 없거나 a가 b보다 나중에 바뀌었는지
+func newer(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return true
+	}
+	return ai.ModTime().After(bi.ModTime())
+}
+
+// 확장자가 .jsonl이면 한 줄에 하나씩 쓴 값으로 읽음. v가 슬라이스가 아니면 첫 줄만 읽음
+func LoadFile(path string, v interface{}) error {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(path) != ".jsonl" {
+		if err := json.Unmarshal(bb, v); err != nil {
+			return errors.Wrap(err, path)
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("%s: cannot decode into %T", path, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(bb))
+	if rv.Elem().Kind() != reflect.Slice {
+		if err := dec.Decode(v); err != nil {
+			return errors.Wrap(err, path)
+		}
+		return nil
+	}
+	s := reflect.MakeSlice(rv.Elem().Type(), 0, 0)
+	for dec.More() {
+		item := reflect.New(s.Type().Elem())
+		if err := dec.Decode(item.Interface()); err != nil {
+			return errors.Wrap(err, path)
+		}
+		s = reflect.Append(s, item.Elem())
+	}
+	rv.Elem().Set(s)
+	return nil
+}
+
+func saveJSONL(path string, data interface{}) error {
+	v := reflect.ValueOf(data)
+	// 배열이 아니면 한 줄로 씀
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		v = reflect.ValueOf([]interface{}{data})
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return errors.Wrap(err, path)
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
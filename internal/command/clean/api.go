@@ -0,0 +1,153 @@
+package clean
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+// slack-go에는 이모지를 지우고 올리는 API가 없어 직접 호출함
+// url을 바꿔 가짜 슬랙 서버로도 테스트할 수 있음
+type slackAPI struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newSlackAPI(apiURL, token string) *slackAPI {
+	if !strings.HasSuffix(apiURL, "/") {
+		apiURL += "/"
+	}
+	return &slackAPI{
+		url:    apiURL,
+		token:  token,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+type emojiListResponse struct {
+	slack.SlackResponse
+	Emoji map[string]string `json:"emoji"`
+}
+
+// 이름 → URL(또는 "alias:원본") 맵
+func (a *slackAPI) listEmojis(ctx context.Context) (map[string]string, error) {
+	var resp emojiListResponse
+	if err := a.post(ctx, "emoji.list", url.Values{}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Emoji, nil
+}
+
+// 원본 이모지를 지우면 그 이모지의 alias도 같이 지워짐
+func (a *slackAPI) removeEmoji(ctx context.Context, name string) error {
+	return a.post(ctx, "admin.emoji.remove", url.Values{"name": {name}}, &slack.SlackResponse{})
+}
+
+func (a *slackAPI) addAlias(ctx context.Context, name, aliasFor string) error {
+	return a.post(ctx, "admin.emoji.addAlias", url.Values{"name": {name}, "alias_for": {aliasFor}}, &slack.SlackResponse{})
+}
+
+// admin.emoji.add는 공개된 URL만 받기에 보관소의 이미지를 그대로 올릴 수 있는 emoji.add를 씀
+func (a *slackAPI) uploadEmoji(ctx context.Context, name, imagePath string, image []byte) error {
+	newRequest := func() (*http.Request, error) {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		if err := w.WriteField("mode", "data"); err != nil {
+			return nil, err
+		}
+		if err := w.WriteField("name", name); err != nil {
+			return nil, err
+		}
+		part, err := w.CreateFormFile("image", filepath.Base(imagePath))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(image); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url+"emoji.add", &body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req, nil
+	}
+	return a.do(ctx, "emoji.add", newRequest, &slack.SlackResponse{})
+}
+
+func (a *slackAPI) post(ctx context.Context, method string, form url.Values, v slackResponse) error {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url+method, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	}
+	return a.do(ctx, method, newRequest, v)
+}
+
+type slackResponse interface {
+	Err() error
+}
+
+// rate limit에 걸리면 Retry-After만큼 기다렸다 다시 시도
+func (a *slackAPI) do(ctx context.Context, method string, newRequest func() (*http.Request, error), v slackResponse) error {
+	for {
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+a.token)
+		resp, err := a.client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if err != nil {
+				retryAfter = 60
+			}
+			log.WithField("method", method).Warnf("rate limited, retry after %ds", retryAfter)
+			timer := time.NewTimer(time.Duration(retryAfter) * time.Second)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		bb, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("%s: %s", method, resp.Status)
+		}
+		if err := json.Unmarshal(bb, v); err != nil {
+			return errors.Wrap(err, method)
+		}
+		return errors.Wrap(v.Err(), method)
+	}
+}
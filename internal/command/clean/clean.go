@@ -0,0 +1,314 @@
+package clean
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/archive"
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+	"emojicleaner/internal/protect"
+)
+
+// stale 결과를 검토해 만든 목록대로 이모지를 지우거나 되살림
+var Command = &cli.Command{
+	Name:  "clean",
+	Short: "remove emojis listed in a reviewed plan, or restore them from the archive with -undo",
+	Setup: setup,
+}
+
+func setup(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+	planPath := fs.String("plan", "", "reviewed plan file, a json list of emojis to remove in the format of stale's unused_emojis.json (default <output-dir>/unused_emojis.json, or .jsonl with -format jsonl)")
+	apply := fs.Bool("apply", false, "actually remove (or restore with -undo) emojis, otherwise only show what would be done")
+	undo := fs.Bool("undo", false, "re-upload emojis removed in the journal from the archive")
+	journalPath := fs.String("journal", "", "audit journal every action is appended to (default <output-dir>/clean_journal.jsonl)")
+	archiveDir := fs.String("archive", "", "emoji archive directory made by download -archive, needed to undo")
+	protectPath := fs.String("protect", "", "json file listing emojis never to remove, the same file given to stale")
+	force := fs.Bool("force", false, "also remove emojis that are not in the archive and cannot be restored")
+	apiURL := fs.String("api-url", slack.APIURL, "slack api url, change it to test against a fake server")
+
+	return func(ctx context.Context) error {
+		journalFile := *journalPath
+		if journalFile == "" {
+			journalFile = g.Path("clean_journal.jsonl")
+		}
+		// 보관소 없이는 아무것도 되살릴 수 없으니 -force 없이는 지우지 않음
+		var index *archive.Index
+		if *archiveDir != "" {
+			var err error
+			if index, err = archive.Load(*archiveDir); err != nil {
+				return err
+			}
+		}
+		protection, err := protect.Load(*protectPath)
+		if err != nil {
+			return err
+		}
+		c := &cleaner{
+			api:        newSlackAPI(*apiURL, g.SlackToken()),
+			archiveDir: *archiveDir,
+			archive:    index,
+			protection: protection,
+			journal:    journalFile,
+			force:      *force,
+			out:        os.Stdout,
+		}
+
+		if *undo {
+			return c.undo(ctx, *apply)
+		}
+		var items []planItem
+		if *planPath == "" {
+			err = g.Load("unused_emojis.json", &items)
+		} else {
+			err = cli.LoadFile(*planPath, &items)
+		}
+		if err != nil {
+			return err
+		}
+		return c.clean(ctx, planNames(items), *apply)
+	}
+}
+
+// 검토를 마친 stale 결과(unused_emojis.json)처럼 name이 있는 목록이면 됨
+type planItem struct {
+	Name string `json:"name"`
+}
+
+func planNames(items []planItem) []string {
+	names := make([]string, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		name := mrkdwn.NormalizeName(item.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Infof("%d emojis are loaded from plan", len(names))
+	return names
+}
+
+type cleaner struct {
+	api        *slackAPI
+	archiveDir string
+	archive    *archive.Index
+	protection *protect.Rules
+	journal    string
+	force      bool
+	out        io.Writer
+}
+
+// 워크스페이스의 지금 이모지와 비교해 지울 목록을 보여주고 apply일 때만 실제로 지움
+func (c *cleaner) clean(ctx context.Context, plan []string, apply bool) error {
+	current, err := c.api.listEmojis(ctx)
+	if err != nil {
+		return err
+	}
+	// 이모지 이름은 자모가 분리된 채로 올 수 있어 정규화한 이름으로 찾고 지울 땐 원래 이름을 씀
+	names := make(map[string]string, len(current))
+	aliases := make(map[string][]string)
+	for name, value := range current {
+		names[mrkdwn.NormalizeName(name)] = name
+		if target, ok := dataset.AliasTarget(value); ok {
+			target := mrkdwn.NormalizeName(target)
+			aliases[target] = append(aliases[target], name)
+		}
+	}
+
+	// 원본을 지울 때 alias도 같이 지워지니 따로 지우면 emoji_not_found로 실패하고 되살릴 때도 두 번 만들게 됨
+	// 그래서 실제로 지울 원본을 먼저 정하고 그 alias만 건너뜀. 원본을 건너뛴다면 alias는 따로 지움
+	entries := make([]journalEntry, len(plan))
+	skips := make([]string, len(plan))
+	removed := make(map[string]bool)
+	for i, name := range plan {
+		entries[i], skips[i] = c.decide(name, names, current, aliases)
+		if skips[i] == "" && entries[i].AliasOf == "" {
+			removed[name] = true
+		}
+	}
+
+	removals := make([]journalEntry, 0, len(plan))
+	for i, name := range plan {
+		e := entries[i]
+		if skips[i] != "" {
+			fmt.Fprint(c.out, skips[i])
+			continue
+		}
+		if target := mrkdwn.NormalizeName(e.AliasOf); e.AliasOf != "" && removed[target] {
+			fmt.Fprintf(c.out, "  :%s: removed with :%s:, skipped\n", name, target)
+			continue
+		}
+		fmt.Fprintf(c.out, "- :%s:\n", name)
+		for _, alias := range e.Aliases {
+			fmt.Fprintf(c.out, "- :%s: (alias of :%s:)\n", alias, name)
+		}
+		removals = append(removals, e)
+	}
+
+	if !apply {
+		log.Infof("dry run, run with -apply to remove %d emojis", len(removals))
+		return nil
+	}
+
+	var failed int
+	for _, e := range removals {
+		err := c.api.removeEmoji(ctx, e.Name)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err != nil {
+			log.WithError(err).Errorf("cannot remove :%s:", e.Name)
+			e.Error = err.Error()
+			failed++
+		}
+		e.Time = time.Now()
+		if err := appendJournal(c.journal, e); err != nil {
+			return err
+		}
+	}
+	log.Infof("%d emojis are removed, %d failed", len(removals)-failed, failed)
+	if failed > 0 {
+		return errors.Errorf("%d emojis are not removed, see %s", failed, c.journal)
+	}
+	return nil
+}
+
+// 계획의 이모지 하나를 지울지 정함. 지우지 않는다면 그 이유를 보여줄 줄을 반환
+func (c *cleaner) decide(name string, names, current map[string]string, aliases map[string][]string) (journalEntry, string) {
+	raw, ok := names[name]
+	if !ok {
+		return journalEntry{}, fmt.Sprintf("  :%s: not found, skipped\n", name)
+	}
+	e := journalEntry{Action: actionRemove, Name: raw}
+	target := name
+	value := current[raw]
+	if aliasOf, ok := dataset.AliasTarget(value); ok {
+		e.AliasOf = aliasOf
+		target = mrkdwn.NormalizeName(aliasOf)
+	}
+	if reason, ok := c.protection.Match(name, target); ok {
+		return e, fmt.Sprintf("! :%s: protected by %s, skipped\n", name, reason)
+	}
+	if e.AliasOf != "" {
+		return e, ""
+	}
+
+	e.URL = value
+	e.Aliases = aliases[name]
+	sort.Strings(e.Aliases)
+	// 원본을 지우면 alias도 같이 지워지니 보호하는 alias가 있다면 원본도 지우지 않음
+	if alias, reason, ok := c.protectedAlias(name, e.Aliases); ok {
+		return e, fmt.Sprintf("! :%s: has alias :%s: protected by %s, skipped\n", name, alias, reason)
+	}
+	if _, ok := c.archive.ImagePath(c.archiveDir, raw); !ok && !c.force {
+		return e, fmt.Sprintf("! :%s: not archived and cannot be restored, skipped\n", name)
+	}
+	return e, ""
+}
+
+func (c *cleaner) protectedAlias(name string, aliases []string) (string, string, bool) {
+	for _, alias := range aliases {
+		if reason, ok := c.protection.Match(mrkdwn.NormalizeName(alias), name); ok {
+			return alias, reason, true
+		}
+	}
+	return "", "", false
+}
+
+// 지운 순서대로 보관소의 이미지를 다시 올리고 같이 지워진 alias도 다시 만듦
+func (c *cleaner) undo(ctx context.Context, apply bool) error {
+	entries, err := loadJournal(c.journal)
+	if err != nil {
+		return err
+	}
+	pending := pendingRestores(entries)
+
+	restores := make([]journalEntry, 0, len(pending))
+	for _, e := range pending {
+		if e.AliasOf == "" {
+			if _, ok := c.archive.ImagePath(c.archiveDir, e.Name); !ok {
+				fmt.Fprintf(c.out, "! :%s: not archived, skipped\n", e.Name)
+				continue
+			}
+		}
+		fmt.Fprintf(c.out, "+ :%s:\n", e.Name)
+		for _, alias := range e.Aliases {
+			fmt.Fprintf(c.out, "+ :%s: (alias of :%s:)\n", alias, e.Name)
+		}
+		restores = append(restores, e)
+	}
+
+	if !apply {
+		log.Infof("dry run, run with -undo -apply to restore %d emojis", len(restores))
+		return nil
+	}
+
+	var failed int
+	for _, removed := range restores {
+		if err := c.restore(ctx, removed); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			failed++
+		}
+	}
+	log.Infof("%d emojis are restored, %d failed", len(restores)-failed, failed)
+	if failed > 0 {
+		return errors.Errorf("%d emojis are not restored, see %s", failed, c.journal)
+	}
+	return nil
+}
+
+func (c *cleaner) restore(ctx context.Context, removed journalEntry) error {
+	e := journalEntry{Action: actionRestore, Name: removed.Name, AliasOf: removed.AliasOf}
+	var err error
+	if removed.AliasOf != "" {
+		err = c.api.addAlias(ctx, removed.Name, removed.AliasOf)
+	} else {
+		p, _ := c.archive.ImagePath(c.archiveDir, removed.Name)
+		var image []byte
+		if image, err = os.ReadFile(p); err == nil {
+			err = c.api.uploadEmoji(ctx, removed.Name, p, image)
+		}
+	}
+	if err := c.record(e, err); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range removed.Aliases {
+		err := c.api.addAlias(ctx, alias, removed.Name)
+		if err := c.record(journalEntry{Action: actionRestore, Name: alias, AliasOf: removed.Name}, err); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 작업 결과를 journal에 남김. journal을 쓰지 못했다면 그 에러를 반환
+func (c *cleaner) record(e journalEntry, err error) error {
+	if err != nil {
+		log.WithError(err).Errorf("cannot restore :%s:", e.Name)
+		e.Error = err.Error()
+	}
+	e.Time = time.Now()
+	return appendJournal(c.journal, e)
+}
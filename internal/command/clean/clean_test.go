@@ -0,0 +1,246 @@
+package clean
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/archive"
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/command/stale"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/protect"
+)
+
+// emoji.list, admin.emoji.remove, emoji.add, admin.emoji.addAlias만 흉내내는 가짜 슬랙 서버
+type fakeSlack struct {
+	mu     sync.Mutex
+	emojis map[string]string
+	images map[string][]byte
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer xoxp-token" {
+		_, _ = w.Write([]byte(`{"ok":false,"error":"not_authed"}`))
+		return
+	}
+	switch r.URL.Path {
+	case "/emoji.list":
+		writeJSON(w, map[string]interface{}{"ok": true, "emoji": f.emojis})
+	case "/admin.emoji.remove":
+		name := r.FormValue("name")
+		if _, ok := f.emojis[name]; !ok {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"emoji_not_found"}`))
+			return
+		}
+		delete(f.emojis, name)
+		for alias, value := range f.emojis {
+			if value == dataset.AliasPrefix+name {
+				delete(f.emojis, alias)
+			}
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	case "/emoji.add":
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"no_image_uploaded"}`))
+			return
+		}
+		defer file.Close()
+		bb, _ := io.ReadAll(file)
+		name := r.FormValue("name")
+		f.emojis[name] = "https://emoji.slack-edge.com/" + name + ".png"
+		f.images[name] = bb
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	case "/admin.emoji.addAlias":
+		f.emojis[r.FormValue("name")] = dataset.AliasPrefix + r.FormValue("alias_for")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func Test_cleaner(t *testing.T) {
+	fake := &fakeSlack{
+		emojis: map[string]string{
+			"party":    "https://emoji.slack-edge.com/party.png",
+			"party2":   "alias:party",
+			"nobak":    "https://emoji.slack-edge.com/nobak.png",
+			"mascot":   "alias:nobak",
+			"keep":     "https://emoji.slack-edge.com/keep.png",
+			"keep2":    "alias:keep",
+			"like":     "alias:+1",
+			"missing":  "https://emoji.slack-edge.com/missing.png",
+			"missing2": "alias:missing",
+		},
+		images: make(map[string][]byte),
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	archiveDir := filepath.Join(dir, "archive")
+	require.NoError(t, os.MkdirAll(filepath.Join(archiveDir, "objects", "ab"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, "objects", "ab", "ab12.png"), []byte("\x89PNG party"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, archive.IndexJSONName), []byte(`{"emojis":{"party":{"path":"objects/ab/ab12.png"}}}`), 0644))
+	index, err := archive.Load(archiveDir)
+	require.NoError(t, err)
+	protection := &protect.Rules{Names: []string{"keep", "mascot"}}
+	require.NoError(t, protection.Compile())
+
+	var out bytes.Buffer
+	c := &cleaner{
+		api:        newSlackAPI(srv.URL, "xoxp-token"),
+		archiveDir: archiveDir,
+		archive:    index,
+		protection: protection,
+		journal:    filepath.Join(dir, "journal.jsonl"),
+		out:        &out,
+	}
+	ctx := context.Background()
+	// party2는 party의 alias라 party를 지울 때 같이 지워짐
+	// keep2와 missing2는 원본을 지우지 않으니 alias만 따로 지움
+	plan := []string{"gone", "keep", "keep2", "like", "missing", "missing2", "nobak", "party", "party2"}
+
+	// dry run은 아무것도 지우지 않음
+	require.NoError(t, c.clean(ctx, plan, false))
+	assert.Equal(t, "  :gone: not found, skipped\n"+
+		"! :keep: protected by name:keep, skipped\n"+
+		"- :keep2:\n"+
+		"- :like:\n"+
+		"! :missing: not archived and cannot be restored, skipped\n"+
+		"- :missing2:\n"+
+		"! :nobak: has alias :mascot: protected by name:mascot, skipped\n"+
+		"- :party:\n"+
+		"- :party2: (alias of :party:)\n"+
+		"  :party2: removed with :party:, skipped\n", out.String())
+	assert.Len(t, fake.emojis, 9)
+	_, err = os.Stat(c.journal)
+	assert.True(t, os.IsNotExist(err))
+
+	out.Reset()
+	require.NoError(t, c.clean(ctx, plan, true))
+	assert.Equal(t, map[string]string{
+		"nobak":   "https://emoji.slack-edge.com/nobak.png",
+		"mascot":  "alias:nobak",
+		"keep":    "https://emoji.slack-edge.com/keep.png",
+		"missing": "https://emoji.slack-edge.com/missing.png",
+	}, fake.emojis)
+	entries, err := loadJournal(c.journal)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, journalEntry{Time: entries[0].Time, Action: actionRemove, Name: "keep2", AliasOf: "keep"}, entries[0])
+	assert.Equal(t, journalEntry{Time: entries[1].Time, Action: actionRemove, Name: "like", AliasOf: "+1"}, entries[1])
+	assert.Equal(t, journalEntry{Time: entries[2].Time, Action: actionRemove, Name: "missing2", AliasOf: "missing"}, entries[2])
+	assert.Equal(t, journalEntry{
+		Time:    entries[3].Time,
+		Action:  actionRemove,
+		Name:    "party",
+		URL:     "https://emoji.slack-edge.com/party.png",
+		Aliases: []string{"party2"},
+	}, entries[3])
+
+	// 지운 이모지를 보관소에서 되살림
+	out.Reset()
+	require.NoError(t, c.undo(ctx, false))
+	assert.Equal(t, "+ :keep2:\n+ :like:\n+ :missing2:\n+ :party:\n+ :party2: (alias of :party:)\n", out.String())
+	require.NoError(t, c.undo(ctx, true))
+	assert.Equal(t, "alias:keep", fake.emojis["keep2"])
+	assert.Equal(t, "alias:+1", fake.emojis["like"])
+	assert.Equal(t, "alias:missing", fake.emojis["missing2"])
+	assert.Equal(t, "alias:party", fake.emojis["party2"])
+	assert.Equal(t, []byte("\x89PNG party"), fake.images["party"])
+
+	// 다 되살렸으니 더 할 게 없음
+	entries, err = loadJournal(c.journal)
+	require.NoError(t, err)
+	assert.Len(t, entries, 9)
+	assert.Empty(t, pendingRestores(entries))
+}
+
+func Test_pendingRestores(t *testing.T) {
+	entries := []journalEntry{
+		{Action: actionRemove, Name: "a"},
+		{Action: actionRemove, Name: "b", Error: "emoji_not_found"},
+		{Action: actionRemove, Name: "c"},
+		{Action: actionRestore, Name: "a"},
+		{Action: actionRestore, Name: "c", Error: "no_image_uploaded"},
+		{Action: actionRemove, Name: "a"},
+	}
+
+	got := pendingRestores(entries)
+
+	assert.Equal(t, []journalEntry{
+		{Action: actionRemove, Name: "c"},
+		{Action: actionRemove, Name: "a"},
+	}, got)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// stale -format jsonl의 결과를 clean이 같은 형식으로 읽고, 예전에 json으로 저장된 결과는 쓰지 않음
+func Test_staleThenClean_jsonl(t *testing.T) {
+	fake := &fakeSlack{
+		emojis: map[string]string{
+			"party":  "https://emoji.slack-edge.com/party.png",
+			"unused": "https://emoji.slack-edge.com/unused.png",
+		},
+		images: make(map[string][]byte),
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+	outDir := filepath.Join(dir, "out")
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "messages"), 0755))
+	require.NoError(t, os.MkdirAll(outDir, 0755))
+	write := func(path, content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(filepath.Join(dataDir, dataset.ManifestJSONName), `{
+		"version": 1,
+		"window": {"oldest": "2022-01-01T00:00:00Z", "latest": "2022-07-01T00:00:00Z"},
+		"complete": true,
+		"channels": [{"id": "C1", "name": "general", "file": "messages/general.json", "oldest": "1640995200", "latest": "1656633600"}]
+	}`)
+	write(filepath.Join(dataDir, dataset.EmojisJSONName), `{
+		"party": "https://emoji.slack-edge.com/party.png",
+		"unused": "https://emoji.slack-edge.com/unused.png"
+	}`)
+	write(filepath.Join(dataDir, "messages", "general.json"), `[{"ts": "1650000000.000100", "user": "U1", "text": "파티 :party:"}]`)
+	// 예전에 json으로 저장한 결과. 이걸 읽으면 party를 지움
+	leftover := filepath.Join(outDir, "unused_emojis.json")
+	write(leftover, `[{"name": "party"}]`)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(leftover, old, old))
+
+	commands := []*cli.Command{stale.Command, Command}
+	global := []string{"emojicleaner", "-dataset", dataDir, "-output-dir", outDir, "-format", "jsonl"}
+	require.Equal(t, cli.ExitOK, cli.Main("dev", commands, append(global, "stale")))
+	require.Equal(t, cli.ExitOK, cli.Main("dev", commands, append(global, "clean", "-apply", "-force", "-token", "xoxp-token", "-api-url", srv.URL)))
+
+	assert.Equal(t, map[string]string{"party": "https://emoji.slack-edge.com/party.png"}, fake.emojis)
+
+	// json으로 읽으려 하면 더 최근의 jsonl 결과가 있어 실패함
+	global[len(global)-1] = cli.FormatJSON
+	assert.Equal(t, cli.ExitError, cli.Main("dev", commands, append(global, "clean", "-apply", "-force", "-token", "xoxp-token", "-api-url", srv.URL)))
+	assert.Len(t, fake.emojis, 1)
+}
@@ -0,0 +1,100 @@
+package clean
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	actionRemove  = "remove"
+	actionRestore = "restore"
+)
+
+// 실제로 호출한 작업을 한 줄에 하나씩 남기는 감사 기록. -undo도 이 기록을 보고 되살림
+type journalEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Name   string    `json:"name"`
+	URL    string    `json:"url,omitempty"`
+	// alias라면 원본 이름
+	AliasOf string `json:"alias_of,omitempty"`
+	// 원본을 지울 때 같이 지워진 alias
+	Aliases []string `json:"aliases,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func (e journalEntry) ok() bool {
+	return e.Error == ""
+}
+
+// 중간에 멈춰도 그때까지 한 작업은 남도록 한 줄씩 바로 씀
+func appendJournal(path string, e journalEntry) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	bb, err := json.Marshal(e)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(bb, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func loadJournal(path string) ([]journalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make([]journalEntry, 0)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, errors.Wrapf(err, "%s:%d", path, line)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// 지운 뒤 아직 되살리지 않은 이모지를 지운 순서대로 반환
+func pendingRestores(entries []journalEntry) []journalEntry {
+	// 이름 → 마지막으로 지운 기록의 위치
+	removed := make(map[string]int)
+	for i, e := range entries {
+		if !e.ok() {
+			continue
+		}
+		switch e.Action {
+		case actionRemove:
+			removed[e.Name] = i
+		case actionRestore:
+			delete(removed, e.Name)
+		}
+	}
+	indexes := make([]int, 0, len(removed))
+	for _, i := range removed {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	pending := make([]journalEntry, 0, len(indexes))
+	for _, i := range indexes {
+		pending = append(pending, entries[i])
+	}
+	return pending
+}
@@ -0,0 +1,247 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/archive"
+	"emojicleaner/internal/dataset"
+)
+
+// 중간에 멈춰도 이어받을 수 있도록 이만큼 받을 때마다 index를 저장
+const archiveSaveInterval = 50
+
+// 이모지를 지우고 나면 이미지를 다시 구할 수 없기에 로컬 보관소에 받아둠
+type emojiArchiver struct {
+	dir         string
+	client      *http.Client
+	concurrency int
+
+	mu    sync.Mutex
+	index *archive.Index
+}
+
+func newEmojiArchiver(dir string, concurrency int) (*emojiArchiver, error) {
+	if err := os.MkdirAll(filepath.Join(dir, archive.ObjectsDir), 0755); err != nil {
+		return nil, err
+	}
+	index, err := archive.Load(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		index = archive.NewIndex()
+	case err != nil:
+		return nil, err
+	default:
+		log.Infof("%d emojis are loaded from archive index", len(index.Emojis))
+	}
+	return &emojiArchiver{
+		dir:         dir,
+		client:      &http.Client{Timeout: time.Minute},
+		concurrency: concurrency,
+		index:       index,
+	}, nil
+}
+
+func (a *emojiArchiver) save() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.index.Save(a.dir)
+}
+
+// GetEmoji로 받은 이름 → URL(또는 "alias:원본") 맵의 이미지를 모두 받아둠
+// 이미 같은 URL로 받아둔 이모지는 건너뛰기에 몇 번을 실행해도 결과가 같음
+func (a *emojiArchiver) archive(ctx context.Context, emojis map[string]string) error {
+	now := time.Now()
+	aliases := make(map[string][]string)
+	names := make([]string, 0, len(emojis))
+	a.mu.Lock()
+	for name, value := range emojis {
+		if target, ok := dataset.AliasTarget(value); ok {
+			aliases[target] = append(aliases[target], name)
+			a.index.Aliases[name] = target
+			continue
+		}
+		names = append(names, name)
+	}
+	for _, name := range names {
+		e, ok := a.index.Emojis[name]
+		if !ok {
+			continue
+		}
+		e.Aliases = aliases[name]
+		sort.Strings(e.Aliases)
+		e.LastSeenAt = now
+		a.index.Emojis[name] = e
+	}
+	a.mu.Unlock()
+	sort.Strings(names)
+
+	jobs := make(chan string)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		fetched  int
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for i := 0; i < a.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				e, err := a.fetch(ctx, name, emojis[name])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = errors.Wrapf(err, "emoji: %s", name)
+						cancel()
+					})
+					return
+				}
+				e.Aliases = aliases[name]
+				sort.Strings(e.Aliases)
+				e.LastSeenAt = now
+
+				a.mu.Lock()
+				a.index.Emojis[name] = e
+				fetched++
+				shouldSave := fetched%archiveSaveInterval == 0
+				a.mu.Unlock()
+				if shouldSave {
+					log.Infof("%d emojis are archived", fetched)
+					if err := a.save(); err != nil {
+						errOnce.Do(func() {
+							firstErr = err
+							cancel()
+						})
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	var skipped int
+loop:
+	for _, name := range names {
+		if a.archived(name, emojis[name]) {
+			skipped++
+			continue
+		}
+		select {
+		case jobs <- name:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// 중단됐더라도 여기까지 받은 이모지는 다음에 다시 받지 않도록 저장
+	if err := a.save(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	log.Infof("%d emojis are archived, %d are already archived", fetched, skipped)
+	return nil
+}
+
+// 같은 URL로 받아둔 이미지 파일이 남아있다면 다시 받지 않음
+func (a *emojiArchiver) archived(name, url string) bool {
+	a.mu.Lock()
+	e, ok := a.index.Emojis[name]
+	a.mu.Unlock()
+	if !ok || e.URL != url {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(a.dir, filepath.FromSlash(e.Path)))
+	return err == nil
+}
+
+func (a *emojiArchiver) fetch(ctx context.Context, name, url string) (archive.Emoji, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return archive.Emoji{}, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return archive.Emoji{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return archive.Emoji{}, errors.Errorf("GET %s: %s", url, resp.Status)
+	}
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return archive.Emoji{}, err
+	}
+
+	sum := sha256.Sum256(bb)
+	hash := hex.EncodeToString(sum[:])
+	rel := path.Join(archive.ObjectsDir, hash[:2], hash+imageExt(url, resp.Header.Get("Content-Type")))
+	if err := writeObject(filepath.Join(a.dir, filepath.FromSlash(rel)), bb); err != nil {
+		return archive.Emoji{}, err
+	}
+	return archive.Emoji{
+		Name:       name,
+		URL:        url,
+		SHA256:     hash,
+		Size:       int64(len(bb)),
+		Path:       rel,
+		ArchivedAt: time.Now(),
+	}, nil
+}
+
+// 이모지 URL은 보통 ".../name/1a2b3c.png" 형태라 확장자를 그대로 쓰고 없다면 Content-Type을 봄
+func imageExt(url, contentType string) string {
+	if ext := path.Ext(strings.SplitN(url, "?", 2)[0]); ext != "" {
+		return strings.ToLower(ext)
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// 같은 내용이면 파일 이름도 같으니 이미 있다면 쓰지 않음
+// 쓰다가 중단돼 깨진 파일이 남지 않도록 임시 파일에 쓴 뒤 옮김
+func writeObject(p string, bb []byte) error {
+	if _, err := os.Stat(p); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bb); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,64 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/archive"
+)
+
+func Test_emojiArchiver_archive(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/parrot/1.gif", "/parrot2/2.gif":
+			_, _ = w.Write([]byte("GIF89a parrot"))
+		case "/tada/3.png":
+			_, _ = w.Write([]byte("\x89PNG tada"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	emojis := map[string]string{
+		"parrot":      srv.URL + "/parrot/1.gif",
+		"parrot2":     srv.URL + "/parrot2/2.gif",
+		"tada":        srv.URL + "/tada/3.png",
+		"partyparrot": "alias:parrot",
+		"like":        "alias:+1",
+	}
+	dir := t.TempDir()
+
+	a, err := newEmojiArchiver(dir, 2)
+	require.NoError(t, err)
+	require.NoError(t, a.archive(context.Background(), emojis))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	index, err := archive.Load(dir)
+	require.NoError(t, err)
+	assert.Len(t, index.Emojis, 3)
+	assert.Equal(t, []string{"partyparrot"}, index.Emojis["parrot"].Aliases)
+	assert.Equal(t, map[string]string{"partyparrot": "parrot", "like": "+1"}, index.Aliases)
+	// 같은 이미지는 한 번만 저장됨
+	assert.Equal(t, index.Emojis["parrot"].Path, index.Emojis["parrot2"].Path)
+	assert.Equal(t, int64(len("\x89PNG tada")), index.Emojis["tada"].Size)
+	bb, err := os.ReadFile(filepath.Join(dir, index.Emojis["tada"].Path))
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG tada", string(bb))
+
+	// 다시 실행해도 이미 받은 이모지는 받지 않음
+	a, err = newEmojiArchiver(dir, 2)
+	require.NoError(t, err)
+	require.NoError(t, a.archive(context.Background(), emojis))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
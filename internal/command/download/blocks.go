@@ -0,0 +1,109 @@
+package download
+
+import (
+	"encoding/json"
+
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+// 메시지 안 "blocks" 필드가 너무 길고 굳이 필요하지 않아 삭제하고 이모지만 남김
+func removeBlocks(msgs []slack.Message) []dataset.Message {
+	removed := make([]dataset.Message, len(msgs))
+	for i, msg := range msgs {
+		emojis := extractBlockEmojis(msg.Blocks.BlockSet)
+		attachments := make([]slack.Attachment, len(msg.Attachments))
+		for j, a := range msg.Attachments {
+			emojis = append(emojis, extractBlockEmojis(a.Blocks.BlockSet)...)
+			a.Blocks = slack.Blocks{BlockSet: nil}
+			attachments[j] = a
+		}
+		if len(attachments) > 0 {
+			msg.Attachments = attachments
+		}
+		msg.Blocks = slack.Blocks{BlockSet: nil}
+		removed[i] = dataset.Message{Message: msg, BlockEmojis: emojis}
+	}
+	return removed
+}
+
+func extractBlockEmojis(blocks []slack.Block) []string {
+	emojis := make([]string, 0)
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *slack.RichTextBlock:
+			for _, e := range b.Elements {
+				emojis = append(emojis, extractRichTextEmojis(e)...)
+			}
+		case *slack.SectionBlock:
+			emojis = append(emojis, extractTextObjectEmojis(b.Text)...)
+			for _, f := range b.Fields {
+				emojis = append(emojis, extractTextObjectEmojis(f)...)
+			}
+		case *slack.ContextBlock:
+			for _, e := range b.ContextElements.Elements {
+				if text, ok := e.(*slack.TextBlockObject); ok {
+					emojis = append(emojis, extractTextObjectEmojis(text)...)
+				}
+			}
+		case *slack.HeaderBlock:
+			emojis = append(emojis, extractTextObjectEmojis(b.Text)...)
+		}
+	}
+	if len(emojis) == 0 {
+		return nil
+	}
+	return emojis
+}
+
+func extractRichTextEmojis(e slack.RichTextElement) []string {
+	emojis := make([]string, 0)
+	switch e := e.(type) {
+	case *slack.RichTextSection:
+		for _, el := range e.Elements {
+			if emoji, ok := el.(*slack.RichTextSectionEmojiElement); ok {
+				emojis = append(emojis, mrkdwn.NormalizeName(emoji.Name))
+			}
+		}
+	case *slack.RichTextUnknown:
+		// slack-go는 rich_text_section 외의 list, quote, preformatted를 풀어주지 않아 직접 훑음
+		var v interface{}
+		if err := json.Unmarshal([]byte(e.Raw), &v); err == nil {
+			emojis = append(emojis, findEmojiElements(v)...)
+		}
+	}
+	return emojis
+}
+
+// {"type": "emoji", "name": "smile"} 형태의 요소를 모두 찾음
+func findEmojiElements(v interface{}) []string {
+	emojis := make([]string, 0)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v["type"] == "emoji" {
+			if name, ok := v["name"].(string); ok {
+				emojis = append(emojis, mrkdwn.NormalizeName(name))
+			}
+		}
+		emojis = append(emojis, findEmojiElements(v["elements"])...)
+	case []interface{}:
+		for _, child := range v {
+			emojis = append(emojis, findEmojiElements(child)...)
+		}
+	}
+	return emojis
+}
+
+func extractTextObjectEmojis(text *slack.TextBlockObject) []string {
+	if text == nil {
+		return nil
+	}
+	emojis := make([]string, 0)
+	// 블록의 mrkdwn, plain_text도 메시지 텍스트와 같은 규칙으로 찾음
+	for _, name := range mrkdwn.Emojis(text.Text) {
+		emojis = append(emojis, mrkdwn.NormalizeName(name))
+	}
+	return emojis
+}
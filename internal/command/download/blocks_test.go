@@ -0,0 +1,60 @@
+package download
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_removeBlocks(t *testing.T) {
+	raw := `{
+		"type": "message",
+		"ts": "1674000000.000100",
+		"text": "배포 완료 :tada:",
+		"blocks": [
+			{"type": "rich_text", "elements": [
+				{"type": "rich_text_section", "elements": [
+					{"type": "text", "text": "배포 완료 "},
+					{"type": "emoji", "name": "tada"}
+				]},
+				{"type": "rich_text_list", "style": "bullet", "elements": [
+					{"type": "rich_text_section", "elements": [{"type": "emoji", "name": "white_check_mark", "skin_tone": 0}]}
+				]}
+			]},
+			{"type": "header", "text": {"type": "plain_text", "text": ":rocket: 릴리즈", "emoji": true}},
+			{"type": "section", "text": {"type": "mrkdwn", "text": "결과 :pray::skin-tone-2:"}, "fields": [{"type": "mrkdwn", "text": ":green:"}]},
+			{"type": "context", "elements": [{"type": "mrkdwn", "text": ":robot_face: by bot"}]},
+			{"type": "divider"}
+		],
+		"attachments": [
+			{"text": "첨부 :memo:", "blocks": [
+				{"type": "section", "text": {"type": "mrkdwn", "text": ":link:"}}
+			]}
+		]
+	}`
+	var msg slack.Message
+	require.NoError(t, json.Unmarshal([]byte(raw), &msg))
+
+	got := removeBlocks([]slack.Message{msg})
+
+	require.Len(t, got, 1)
+	assert.Equal(t, []string{"tada", "white_check_mark", "rocket", "pray", "green", "robot_face", "link"}, got[0].BlockEmojis)
+	assert.Empty(t, got[0].Blocks.BlockSet)
+	assert.Empty(t, got[0].Attachments[0].Blocks.BlockSet)
+	// 첨부의 텍스트는 그대로 남아 분석할 때 따로 셈
+	assert.Equal(t, "첨부 :memo:", got[0].Attachments[0].Text)
+	// 원본 메시지는 건드리지 않음
+	assert.NotEmpty(t, msg.Attachments[0].Blocks.BlockSet)
+
+	bb, err := json.Marshal(got[0])
+	require.NoError(t, err)
+	var stored dataset.Message
+	require.NoError(t, json.Unmarshal(bb, &stored))
+	assert.Equal(t, got[0].BlockEmojis, stored.BlockEmojis)
+	assert.Equal(t, "1674000000.000100", stored.Timestamp)
+}
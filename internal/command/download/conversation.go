@@ -0,0 +1,161 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/dataset"
+)
+
+const (
+	publicChannel  = "public_channel"
+	privateChannel = "private_channel"
+	mpim           = "mpim"
+	im             = "im"
+)
+
+// 대화 종류별로 목록과 메시지를 불러오는데 필요한 User Token Scope
+var requiredScopes = map[string][]string{
+	publicChannel:  {"channels:read", "channels:history"},
+	privateChannel: {"groups:read", "groups:history"},
+	mpim:           {"mpim:read", "mpim:history"},
+	im:             {"im:read", "im:history"},
+}
+
+// "public_channel,private_channel" 같은 -types 값을 검사해서 나눠줌
+func parseConversationTypes(s string) ([]string, error) {
+	types := make([]string, 0, len(requiredScopes))
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if _, ok := requiredScopes[t]; !ok {
+			return nil, errors.Errorf("unknown conversation type '%s'", t)
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return nil, errors.New("at least one conversation type is required")
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+func conversationType(ch slack.Channel) string {
+	switch {
+	case ch.IsIM:
+		return im
+	case ch.IsMpIM:
+		return mpim
+	case ch.IsPrivate || ch.IsGroup:
+		return privateChannel
+	default:
+		return publicChannel
+	}
+}
+
+// DM은 이름이 없어 상대방 ID로 대신함
+func channelName(ch slack.Channel) string {
+	if ch.IsIM {
+		return "dm-" + ch.User
+	}
+	return ch.Name
+}
+
+type authInfo struct {
+	slack.SlackResponse
+	Team   string   `json:"team"`
+	TeamID string   `json:"team_id"`
+	Scopes []string `json:"-"`
+}
+
+// slack-go는 응답 헤더를 알려주지 않아 토큰의 scope를 확인하려면 auth.test를 직접 호출해야 함
+func fetchAuth(ctx context.Context, token string) (authInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slack.APIURL+"auth.test", nil)
+	if err != nil {
+		return authInfo{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return authInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	var auth authInfo
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return authInfo{}, errors.Wrap(err, "auth.test")
+	}
+	if err := auth.Err(); err != nil {
+		return authInfo{}, err
+	}
+
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			auth.Scopes = append(auth.Scopes, scope)
+		}
+	}
+	return auth, nil
+}
+
+// 받으려는 대화 종류에 필요한 scope가 토큰에 없다면 어떤 scope를 추가해야 하는지 알려줌
+func checkScopes(types, scopes []string) error {
+	// 헤더를 못 받은 경우라면 일단 진행하고 실제 호출에서 missing_scope 에러를 받음
+	if len(scopes) == 0 {
+		log.Warn("cannot check token scopes, continue anyway")
+		return nil
+	}
+
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	missing := make([]string, 0)
+	for _, t := range types {
+		for _, scope := range requiredScopes[t] {
+			if !granted[scope] {
+				missing = append(missing, scope)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("missing_scope: add %s to User Token Scopes", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// 어떤 종류의 대화를 불러왔는지 같이 저장해둬야 -types가 바뀌었을 때 다시 불러올 수 있음
+type channelCache struct {
+	Types    []string        `json:"types"`
+	Channels []slack.Channel `json:"channels"`
+}
+
+func (c channelCache) covers(types []string) bool {
+	cached := make(map[string]bool, len(c.Types))
+	for _, t := range c.Types {
+		cached[t] = true
+	}
+	for _, t := range types {
+		if !cached[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// 대화 종류별로 받은 채널 수와 건너뛴 채널 수
+func logSources(r *dataset.Sources) {
+	for _, t := range r.Types {
+		log.Infof("%s: %d downloaded, %d skipped", t, r.Downloaded[t], r.Skipped[t])
+	}
+}
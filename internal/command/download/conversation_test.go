@@ -0,0 +1,26 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseConversationTypes(t *testing.T) {
+	got, err := parseConversationTypes("public_channel, im,private_channel,im")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"im", "private_channel", "public_channel"}, got)
+
+	_, err = parseConversationTypes("public_channel,group")
+	assert.Error(t, err)
+	_, err = parseConversationTypes("")
+	assert.Error(t, err)
+}
+
+func Test_checkScopes(t *testing.T) {
+	scopes := []string{"channels:read", "channels:history", "emoji:read", "groups:read"}
+
+	assert.NoError(t, checkScopes([]string{publicChannel}, scopes))
+	err := checkScopes([]string{privateChannel, publicChannel}, scopes)
+	assert.EqualError(t, err, "missing_scope: add groups:history to User Token Scopes")
+}
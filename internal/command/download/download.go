@@ -0,0 +1,762 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+const (
+	dateLayout  = "2006-01-02"
+	defaultDays = 30
+)
+
+var (
+	errNotInChannel = errors.New("not in channel")
+)
+
+// 슬랙 채널, 이모지, 메시지를 데이터셋으로 받음
+var Command = &cli.Command{
+	Name:  "download",
+	Short: "download emojis, channels and messages of a workspace into the dataset",
+	Setup: setup,
+}
+
+func setup(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+	since := fs.String("since", "", "download messages on or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "download messages on or before this date (YYYY-MM-DD), defaults to now")
+	days := fs.Int("days", 0, fmt.Sprintf("download messages of the last n days, counted back from -until (default %d when -since is not set)", defaultDays))
+	threadLookback := fs.Int("thread-lookback", 0, "also scan threads started up to n days before the window, or before the last sync when resuming, for replies posted within it")
+	concurrency := fs.Int("concurrency", 4, "number of channels to download at the same time")
+	typesFlag := fs.String("types", publicChannel, "comma separated conversation types to download: public_channel, private_channel, mpim, im")
+	filterConfig := fs.String("filter-config", "", "json file with channel include/exclude rules")
+	var includes, excludes stringsFlag
+	fs.Var(&includes, "include", "download only channels matching this rule: name glob, 're:<regexp>' or 'id:<channel id>' (repeatable)")
+	fs.Var(&excludes, "exclude", "skip channels matching this rule: name glob, 're:<regexp>' or 'id:<channel id>' (repeatable)")
+	minMembers := fs.Int("min-members", 0, "skip channels with fewer members than this")
+	createdBefore := fs.String("created-before", "", "download only channels created before this date (YYYY-MM-DD)")
+	archiveDir := fs.String("archive", "", "also download every custom emoji image into this archive directory")
+	skipMessages := fs.Bool("skip-messages", false, "download only emojis, not channels and messages")
+	emojiMetadata := fs.Bool("emoji-metadata", false, "also download creation date and uploader of custom emojis with admin.emoji.list (Enterprise Grid admin token only)")
+
+	return func(ctx context.Context) error {
+		err := download(ctx, g, options{
+			since:          *since,
+			until:          *until,
+			days:           *days,
+			threadLookback: *threadLookback,
+			concurrency:    *concurrency,
+			types:          *typesFlag,
+			filterConfig:   *filterConfig,
+			includes:       includes,
+			excludes:       excludes,
+			minMembers:     *minMembers,
+			createdBefore:  *createdBefore,
+			archiveDir:     *archiveDir,
+			skipMessages:   *skipMessages,
+			emojiMetadata:  *emojiMetadata,
+		})
+		if errors.Is(err, context.Canceled) {
+			log.Info("channels downloaded so far are saved and will be resumed next time")
+		}
+		return err
+	}
+}
+
+// 플래그로 받은 값
+type options struct {
+	since          string
+	until          string
+	days           int
+	threadLookback int
+	concurrency    int
+	types          string
+	filterConfig   string
+	includes       stringsFlag
+	excludes       stringsFlag
+	minMembers     int
+	createdBefore  string
+	archiveDir     string
+	skipMessages   bool
+	emojiMetadata  bool
+}
+
+func download(ctx context.Context, g *cli.Globals, o options) error {
+	if o.threadLookback < 0 {
+		return cli.UsageErrorf("-thread-lookback must be positive: %d", o.threadLookback)
+	}
+	if o.concurrency < 1 {
+		return cli.UsageErrorf("-concurrency must be positive: %d", o.concurrency)
+	}
+	types, err := parseConversationTypes(o.types)
+	if err != nil {
+		return cli.UsageErrorf("-types: %s", err)
+	}
+	log.Infof("downloading %s", strings.Join(types, ", "))
+
+	// 설정 파일의 규칙에 플래그로 받은 규칙을 더함
+	filter, err := loadChannelFilter(o.filterConfig)
+	if err != nil {
+		return err
+	}
+	filter.Include = append(filter.Include, o.includes...)
+	filter.Exclude = append(filter.Exclude, o.excludes...)
+	if o.minMembers != 0 {
+		filter.MinMembers = o.minMembers
+	}
+	if o.createdBefore != "" {
+		filter.CreatedBefore = o.createdBefore
+	}
+	if err := filter.compile(); err != nil {
+		return err
+	}
+	for _, t := range types {
+		if t == mpim || t == im {
+			log.Warn("direct messages will be saved as plain json files, handle them with care")
+			break
+		}
+	}
+
+	w, err := parseWindow(o.since, o.until, o.days, time.Now())
+	if err != nil {
+		return cli.UsageErrorf("%s", err)
+	}
+	log.Infof("downloading messages from %s to %s", w.Oldest.Format(time.RFC3339), w.Latest.Format(time.RFC3339))
+
+	if err := os.MkdirAll(filepath.Join(g.Dataset, dataset.MessagesDirName), 0755); err != nil {
+		return err
+	}
+	m, err := loadManifest(g.Dataset)
+	if err != nil {
+		return err
+	}
+
+	slackBotToken := g.SlackToken()
+
+	d := &downloader{
+		client:         slack.New(slackBotToken),
+		limiter:        newRateLimiter(),
+		window:         w,
+		threadLookback: o.threadLookback,
+		concurrency:    o.concurrency,
+		types:          types,
+		filter:         filter,
+		dataset:        g.Dataset,
+		manifest:       m,
+	}
+	var auth authInfo
+	if err := d.limiter.call(ctx, "auth.test", func() (err error) {
+		auth, err = fetchAuth(ctx, slackBotToken)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := checkScopes(types, auth.Scopes); err != nil {
+		return err
+	}
+	// 다른 워크스페이스의 메시지가 섞이지 않도록 막음
+	if m.WorkspaceID != "" && m.WorkspaceID != auth.TeamID {
+		return errors.Errorf("'%s' is a dataset of workspace %s(%s), not %s(%s)", g.Dataset, m.Workspace, m.WorkspaceID, auth.Team, auth.TeamID)
+	}
+
+	// 1. 이모지를 불러오고 로컬에 저장한다. 저장된 이모지가 있다면 해당 파일을 불러옴
+	emojis, err := d.saveEmojis(ctx)
+	if err != nil {
+		return err
+	}
+	// stale이 만든 지 얼마 안 된 이모지를 구분할 수 있도록 만든 날짜와 만든 사람도 받아둠
+	if o.emojiMetadata {
+		if err := d.saveEmojiMetadata(ctx, slackBotToken); err != nil {
+			return err
+		}
+	}
+	// 상태 이모지도 사용 중인 것으로 볼 수 있도록 받아둠
+	if hasScope(auth.Scopes, surfaceScopes[dataset.UsersJSONName]) {
+		if err := d.saveUserStatuses(ctx); err != nil {
+			return err
+		}
+	} else {
+		log.Warnf("missing_scope: add %s to User Token Scopes to count status emojis", surfaceScopes[dataset.UsersJSONName])
+	}
+	// 지운 이모지를 되살릴 수 있도록 이미지도 받아둠
+	if o.archiveDir != "" {
+		archiver, err := newEmojiArchiver(o.archiveDir, o.concurrency)
+		if err != nil {
+			return err
+		}
+		if err := archiver.archive(ctx, emojis); err != nil {
+			return err
+		}
+	}
+	if o.skipMessages {
+		return nil
+	}
+
+	// 분석할 때 어느 워크스페이스의 어느 기간 데이터인지 알 수 있도록 기록. 다 받기 전까진 Complete가 false
+	m.ToolVersion = g.Version
+	m.WorkspaceID = auth.TeamID
+	m.Workspace = auth.Team
+	m.Window = w
+	m.Sources = dataset.NewSources(types)
+	m.Complete = false
+	if err := m.Save(g.Dataset); err != nil {
+		return err
+	}
+
+	if hasScope(auth.Scopes, surfaceScopes[dataset.BookmarksJSONName]) {
+		d.bookmarks = make(map[string][]channelBookmark)
+	} else {
+		log.Warnf("missing_scope: add %s to User Token Scopes to count bookmark emojis", surfaceScopes[dataset.BookmarksJSONName])
+	}
+
+	// 2. 조사할 채널을 불러온다. 저장된 채널 목록이 있다면 해당 파일을 불러옴
+	channels, err := d.loadChannels(ctx)
+	if err != nil {
+		return err
+	}
+	// 채널 목록은 캐시를 쓰지만 토픽과 목적은 지금 걸려있는 것을 세야 하니 매번 새로 받음
+	if err := d.saveTopics(ctx, channels); err != nil {
+		return err
+	}
+
+	// 3. 채널을 돌면서 최근 n일 메시지를 불러와 저장함. 이전에 받아둔 채널이라면 그 이후 메시지만 불러와 합침
+	if err := d.run(ctx, channels); err != nil {
+		return err
+	}
+	if err := d.saveBookmarks(); err != nil {
+		return err
+	}
+
+	logSources(m.Sources)
+	m.Complete = true
+	m.DownloadedAt = time.Now()
+	return m.Save(g.Dataset)
+}
+
+// 아직 받은 적 없는 데이터셋이라면 빈 manifest를 반환
+func loadManifest(dir string) (*dataset.Manifest, error) {
+	m, err := dataset.ReadManifest(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return &dataset.Manifest{Version: dataset.ManifestVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("%d channels are loaded from manifest", len(m.Channels))
+	return m, nil
+}
+
+type downloader struct {
+	client         *slack.Client
+	limiter        *rateLimiter
+	window         dataset.Window
+	threadLookback int
+	concurrency    int
+	types          []string
+	filter         *channelFilter
+	dataset        string
+
+	// 여러 워커가 동시에 갱신하기에 mu로 보호
+	mu       sync.Mutex
+	manifest *dataset.Manifest
+	// 채널 ID → 북마크. nil이면 북마크를 받지 않음
+	bookmarks map[string][]channelBookmark
+}
+
+// -since, -until은 날짜 단위라 -until로 받은 날짜는 그 날 끝까지 포함함
+// -since가 없으면 -until(없으면 지금)로부터 -days일 전부터 불러옴
+func parseWindow(since, until string, days int, now time.Time) (dataset.Window, error) {
+	if since != "" && days != 0 {
+		return dataset.Window{}, errors.New("-since and -days cannot be used together")
+	}
+	if days < 0 {
+		return dataset.Window{}, errors.Errorf("-days must be positive: %d", days)
+	}
+
+	w := dataset.Window{Latest: now}
+	if until != "" {
+		t, err := time.ParseInLocation(dateLayout, until, time.Local)
+		if err != nil {
+			return dataset.Window{}, errors.Wrap(err, "-until")
+		}
+		w.Latest = t.AddDate(0, 0, 1)
+	}
+	if since != "" {
+		t, err := time.ParseInLocation(dateLayout, since, time.Local)
+		if err != nil {
+			return dataset.Window{}, errors.Wrap(err, "-since")
+		}
+		w.Oldest = t
+	} else {
+		if days == 0 {
+			days = defaultDays
+		}
+		w.Oldest = w.Latest.AddDate(0, 0, -days)
+	}
+
+	if !w.Oldest.Before(w.Latest) {
+		return dataset.Window{}, errors.Errorf("empty window: %s ~ %s", w.Oldest.Format(dateLayout), w.Latest.Format(dateLayout))
+	}
+	return w, nil
+}
+
+func (d *downloader) saveEmojis(ctx context.Context) (map[string]string, error) {
+	var emojis map[string]string
+	err := d.limiter.call(ctx, "emoji.list", func() (err error) {
+		emojis, err = d.client.GetEmojiContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "GetEmoji")
+	}
+	emojis = normalizeEmojis(emojis)
+	return emojis, dataset.SaveJSON(filepath.Join(d.dataset, dataset.EmojisJSONName), emojis)
+}
+
+// 이모지를 만들 때 윈도우와 맥의 동작이 다른걸로 추정
+// 어쨌든 유니코드를 정규화해서 자모분리가 되지 않도록 수정해줌
+// "alias:원본"의 원본 이름도 같은 이모지를 가리키도록 똑같이 정규화
+func normalizeEmojis(emojis map[string]string) map[string]string {
+	m := make(map[string]string, len(emojis))
+	for k, v := range emojis {
+		if target, ok := dataset.AliasTarget(v); ok {
+			v = dataset.AliasPrefix + mrkdwn.NormalizeName(target)
+		}
+		m[mrkdwn.NormalizeName(k)] = v
+	}
+	return m
+}
+
+// 아카이브된 채널을 제외하고 -types로 지정한 종류의 대화를 모두 불러온다
+func (d *downloader) listChannels(ctx context.Context) ([]slack.Channel, error) {
+	channels := make([]slack.Channel, 0)
+	var cursor string
+	for {
+		var chs []slack.Channel
+		var nextCursor string
+		err := d.limiter.call(ctx, "conversations.list", func() (err error) {
+			chs, nextCursor, err = d.client.GetConversationsContext(ctx, &slack.GetConversationsParameters{
+				Cursor:          cursor,
+				ExcludeArchived: true,
+				Types:           d.types,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "GetConversations")
+		}
+		channels = append(channels, chs...)
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+	return channels, nil
+}
+
+func (d *downloader) saveChannels(ctx context.Context) error {
+	channels, err := d.listChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	return dataset.SaveJSON(filepath.Join(d.dataset, dataset.ChannelsJSONName), channelCache{
+		Types:    d.types,
+		Channels: channels,
+	})
+}
+
+func (d *downloader) loadChannels(ctx context.Context) ([]slack.Channel, error) {
+	path := filepath.Join(d.dataset, dataset.ChannelsJSONName)
+	// Read channels from file
+	bb, err := os.ReadFile(path)
+	// If not exist, fetch channels and save/load it
+	if errors.Is(err, os.ErrNotExist) {
+		log.Infof("'%s' is not exist", path)
+		if err := d.saveChannels(ctx); err != nil {
+			return nil, err
+		}
+		return d.loadChannels(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 예전처럼 퍼블릭 채널만 배열로 저장돼있거나 이번에 받으려는 종류가 빠져있다면 다시 불러옴
+	var cache channelCache
+	if err := json.Unmarshal(bb, &cache); err != nil || !cache.covers(d.types) {
+		log.Infof("'%s' does not cover %s", path, strings.Join(d.types, ", "))
+		if err := d.saveChannels(ctx); err != nil {
+			return nil, err
+		}
+		return d.loadChannels(ctx)
+	}
+
+	wanted := make(map[string]bool, len(d.types))
+	for _, t := range d.types {
+		wanted[t] = true
+	}
+	channels := make([]slack.Channel, 0, len(cache.Channels))
+	var filtered int
+	for _, ch := range cache.Channels {
+		if !wanted[conversationType(ch)] {
+			continue
+		}
+		if !d.filter.match(ch) {
+			log.WithField("channel", "#"+channelName(ch)).Debug("filtered out")
+			filtered++
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	log.Infof("%d channels are loaded from json file, %d are filtered out", len(channels), filtered)
+	return channels, nil
+}
+
+// 채널을 concurrency개의 워커에 나눠 받음. 하나라도 실패하면 나머지도 멈추고 첫 에러를 반환
+func (d *downloader) run(ctx context.Context, channels []slack.Channel) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan slack.Channel)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < d.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for channel := range jobs {
+				if err := d.downloadChannel(ctx, channel); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+loop:
+	for _, channel := range channels {
+		select {
+		case jobs <- channel:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (d *downloader) downloadChannel(ctx context.Context, channel slack.Channel) error {
+	name := channelName(channel)
+	entry := log.WithField("channel", "#"+name)
+
+	// 혹시 아카이브된 채널이면 pass
+	if channel.IsArchived {
+		entry.Info("skipped archived channel")
+		d.count(channel, false)
+		return nil
+	}
+
+	// 북마크는 기간과 상관없이 지금 걸려있는 것을 받기에 이어받는 채널도 매번 새로 받음
+	if err := d.collectBookmarks(ctx, channel); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dataset.MessagesDirName, name+".json")
+	path := filepath.Join(d.dataset, file)
+	stored, err := loadMessages(path)
+	if err != nil {
+		return errors.Wrapf(err, "channel: %s", name)
+	}
+
+	// 이어받을 수 없다면 처음부터 다시 받아 합침
+	w := d.window
+	oldest := w.OldestTS()
+	cp, _ := d.checkpoint(channel.ID)
+	if canResume(cp, stored, oldest) {
+		oldest = cp.Latest
+		entry.Infof("resuming after %s", cp.Latest)
+	}
+
+	msgs, err := d.listMessages(ctx, channel, oldest, w.LatestTS())
+	// 채널에 들어가있지 않더라도 불러올 수야 있지만 혹시몰라 하는 에러 핸들링
+	if errors.Is(err, errNotInChannel) {
+		entry.Error("not in channel")
+		d.count(channel, false)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 이번에 받은 구간보다 먼저 시작된 스레드에도 새 답글이 달렸을 수 있어 -thread-lookback일 전까지 훑어봄
+	// 이어받은 경우 저장된 기간을 통째로 다시 훑으면 처음부터 받는 것과 history 호출 수가 같아지니 이어받은 시점부터 셈
+	scanOldest := shiftTS(oldest, -d.threadLookback)
+	if dataset.TSLess(scanOldest, oldest) {
+		replies, err := d.listActiveThreadReplies(ctx, channel, scanOldest, oldest, oldest, w.LatestTS())
+		if err != nil {
+			return err
+		}
+		entry.Infof("fetched %d replies in threads started before %s", len(replies), oldest)
+		msgs = append(msgs, replies...)
+	}
+
+	entry.Infof("fetched %d messages", len(msgs))
+	merged := mergeMessages(stored, removeBlocks(msgs), w)
+	if err := dataset.SaveJSON(path, merged); err != nil {
+		return errors.Wrapf(err, "channel: %s", name)
+	}
+	d.count(channel, true)
+
+	// 기간 밖의 메시지는 합치면서 버리기 때문에 저장된 메시지는 정확히 이번 기간을 담고 있음
+	// 중간에 ctrl+c로 중단해도 여기까지 받은 채널은 다음 실행 때 이어받을 수 있도록 매번 저장
+	return d.saveChannel(dataset.Channel{
+		ID:       channel.ID,
+		Name:     name,
+		Type:     conversationType(channel),
+		File:     filepath.ToSlash(file),
+		Messages: len(merged),
+		Oldest:   w.OldestTS(),
+		Latest:   w.LatestTS(),
+		SyncedAt: time.Now(),
+	})
+}
+
+func (d *downloader) count(channel slack.Channel, downloaded bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if downloaded {
+		d.manifest.Sources.Downloaded[conversationType(channel)]++
+	} else {
+		d.manifest.Sources.Skipped[conversationType(channel)]++
+	}
+}
+
+// 저장된 메시지와 체크포인트가 모두 있고 저장된 기간이 이번 기간의 시작(oldest)을 포함해야 이어받음
+// 체크포인트가 없다면 cp는 빈 값
+func canResume(cp dataset.Channel, stored []dataset.Message, oldest string) bool {
+	return stored != nil && cp.Oldest != "" && !dataset.TSLess(oldest, cp.Oldest) && dataset.TSLess(oldest, cp.Latest)
+}
+
+func (d *downloader) checkpoint(channelID string) (dataset.Channel, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.manifest.Channel(channelID)
+}
+
+func (d *downloader) saveChannel(ch dataset.Channel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.manifest.SetChannel(ch)
+	return d.manifest.Save(d.dataset)
+}
+
+// 저장된 파일이 없으면 nil을 반환
+func loadMessages(path string) ([]dataset.Message, error) {
+	bb, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]dataset.Message, 0, 200)
+	if err := json.Unmarshal(bb, &msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// ts를 기준으로 중복을 제거하며 합침. 같은 메시지라면 리액션 등이 갱신됐을 수 있으니 새로 받은 쪽을 사용
+// 기간이 옮겨갔을 수 있으니 기간 밖의 메시지는 버림
+func mergeMessages(stored, fetched []dataset.Message, w dataset.Window) []dataset.Message {
+	m := make(map[string]dataset.Message, len(stored)+len(fetched))
+	for _, msg := range stored {
+		m[msg.Timestamp] = msg
+	}
+	for _, msg := range fetched {
+		m[msg.Timestamp] = msg
+	}
+	for ts := range m {
+		if !w.Contains(ts) {
+			delete(m, ts)
+		}
+	}
+
+	merged := make([]dataset.Message, 0, len(m))
+	for _, msg := range m {
+		merged = append(merged, msg)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return dataset.TSLess(merged[i].Timestamp, merged[j].Timestamp)
+	})
+	return merged
+}
+
+// 초 단위 ts를 days일 옮김
+func shiftTS(ts string, days int) string {
+	t, _ := dataset.ParseTS(ts)
+	return strconv.FormatInt(t.AddDate(0, 0, days).Unix(), 10)
+}
+
+// oldest와 latest 사이의 채널 메시지와 그 스레드에 달린 답글 중 같은 기간에 달린 것들을 불러옴
+func (d *downloader) listMessages(ctx context.Context, channel slack.Channel, oldest, latest string) ([]slack.Message, error) {
+	messages := make([]slack.Message, 0, 200)
+	err := d.walkHistory(ctx, channel, oldest, latest, func(message slack.Message) error {
+		// "thread_broadcast" 타입은 본문 스레드에서 가져올 수 있어 넘어감
+		if message.SubType == "channel_join" ||
+			message.SubType == "channel_leave" ||
+			message.SubType == "thread_broadcast" {
+			return nil
+		}
+		messages = append(messages, message)
+		// 위에선 스레드 메시지가 아니라 채널 메시지만 가져오기에 만약 스레드가 있다면 별도로 가져와줘야함
+		if message.ReplyCount > 0 {
+			replies, err := d.listMessagesInThread(ctx, channel, message.Timestamp, oldest, latest)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, replies...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// 기간보다 오래된 스레드라도 기간 안에 답글이 달렸다면 그 답글은 기간 안에 사용된 이모지라 챙겨야 함
+// [scanOldest, scanLatest) 사이의 채널 메시지 중 repliedAfter 이후에 답글이 달린 스레드를 찾아
+// repliedAfter와 latest 사이에 달린 답글만 불러옴. 스레드 본문은 기간 밖이라 포함하지 않음
+func (d *downloader) listActiveThreadReplies(ctx context.Context, channel slack.Channel, scanOldest, scanLatest, repliedAfter, latest string) ([]slack.Message, error) {
+	messages := make([]slack.Message, 0, 100)
+	err := d.walkHistory(ctx, channel, scanOldest, scanLatest, func(message slack.Message) error {
+		if message.ReplyCount == 0 || !dataset.TSLess(repliedAfter, message.LatestReply) {
+			return nil
+		}
+		replies, err := d.listMessagesInThread(ctx, channel, message.Timestamp, repliedAfter, latest)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, replies...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// 채널 메시지를 페이지 단위로 불러오며 하나씩 handle에 넘겨줌
+func (d *downloader) walkHistory(ctx context.Context, channel slack.Channel, oldest, latest string, handle func(slack.Message) error) error {
+	cursor := ""
+	for {
+		var resp *slack.GetConversationHistoryResponse
+		err := d.limiter.call(ctx, "conversations.history", func() (err error) {
+			resp, err = d.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: channel.ID,
+				Cursor:    cursor,
+				Oldest:    oldest,
+				Latest:    latest,
+			})
+			return err
+		})
+		if err != nil {
+			if err.Error() == "not_in_channel" {
+				return errNotInChannel
+			}
+			return err
+		}
+
+		for _, message := range resp.Messages {
+			if err := handle(message); err != nil {
+				return err
+			}
+		}
+
+		if !resp.HasMore || resp.ResponseMetadata.Cursor == "" {
+			break
+		}
+
+		cursor = resp.ResponseMetadata.Cursor
+	}
+	return nil
+}
+
+// 스레드 본문을 제외하고 oldest와 latest 사이에 달린 답글만 불러옴
+func (d *downloader) listMessagesInThread(ctx context.Context, channel slack.Channel, ts, oldest, latest string) ([]slack.Message, error) {
+	messages := make([]slack.Message, 0, 100)
+	cursor := ""
+	for {
+		var resp []slack.Message
+		var hasMore bool
+		var nextCursor string
+		err := d.limiter.call(ctx, "conversations.replies", func() (err error) {
+			resp, hasMore, nextCursor, err = d.client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channel.ID,
+				Timestamp: ts,
+				Cursor:    cursor,
+				Oldest:    oldest,
+				Latest:    latest,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, filterReplies(resp, ts, oldest, latest)...)
+
+		if !hasMore || nextCursor == "" {
+			break
+		}
+
+		cursor = nextCursor
+	}
+	return messages, nil
+}
+
+// conversations.replies는 oldest, latest와 상관없이 스레드 본문을 항상 같이 주고
+// 경계값도 헷갈리기 쉬워 답글 각각의 ts로 다시 걸러줌
+func filterReplies(msgs []slack.Message, threadTS, oldest, latest string) []slack.Message {
+	filtered := make([]slack.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.Timestamp == threadTS {
+			continue
+		}
+		if dataset.TSLess(msg.Timestamp, oldest) || dataset.TSLess(latest, msg.Timestamp) {
+			continue
+		}
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
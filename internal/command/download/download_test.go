@@ -0,0 +1,197 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_mergeMessages(t *testing.T) {
+	msg := func(ts, text string) dataset.Message {
+		return dataset.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: ts, Text: text}}}
+	}
+	stored := []dataset.Message{
+		msg("1674000000.000100", "a"),
+		msg("1674000100.000100", "b"),
+	}
+	fetched := []dataset.Message{
+		msg("1674000200.000100", "c"),
+		msg("1674000100.000100", "b (edited)"),
+		msg("1674009999.000100", "out of window"),
+	}
+	w := dataset.Window{Oldest: time.Unix(1674000000, 0), Latest: time.Unix(1674001000, 0)}
+
+	got := mergeMessages(stored, fetched, w)
+
+	assert.Equal(t, []dataset.Message{
+		msg("1674000000.000100", "a"),
+		msg("1674000100.000100", "b (edited)"),
+		msg("1674000200.000100", "c"),
+	}, got)
+}
+
+func Test_canResume(t *testing.T) {
+	stored := []dataset.Message{}
+	cp := dataset.Channel{ID: "C1", Oldest: "1674000000", Latest: "1674001000"}
+	cases := []struct {
+		name     string
+		cp       dataset.Channel
+		stored   []dataset.Message
+		oldest   string
+		expected bool
+	}{
+		{name: "no checkpoint", cp: dataset.Channel{}, stored: stored, oldest: "1674000500", expected: false},
+		{name: "stored file missing", cp: cp, stored: nil, oldest: "1674000500", expected: false},
+		{name: "window earlier than checkpoint", cp: cp, stored: stored, oldest: "1673999999", expected: false},
+		{name: "window starts after checkpoint", cp: cp, stored: stored, oldest: "1674001000", expected: false},
+		{name: "resume", cp: cp, stored: stored, oldest: "1674000000", expected: true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, canResume(c.cp, c.stored, c.oldest), c.name)
+	}
+}
+
+func Test_parseWindow(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	cases := []struct {
+		name     string
+		since    string
+		until    string
+		days     int
+		expected dataset.Window
+	}{
+		{
+			name:     "기본 30일",
+			expected: dataset.Window{Oldest: now.AddDate(0, 0, -30), Latest: now},
+		},
+		{
+			name:     "최근 90일",
+			days:     90,
+			expected: dataset.Window{Oldest: now.AddDate(0, 0, -90), Latest: now},
+		},
+		{
+			name:     "절대 기간은 until 날짜를 포함",
+			since:    "2024-01-01",
+			until:    "2024-12-31",
+			expected: dataset.Window{Oldest: date(2024, 1, 1), Latest: date(2025, 1, 1)},
+		},
+		{
+			name:     "until로부터 n일",
+			until:    "2024-12-31",
+			days:     7,
+			expected: dataset.Window{Oldest: date(2024, 12, 25), Latest: date(2025, 1, 1)},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseWindow(tc.since, tc.until, tc.days, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	t.Run("잘못된 입력", func(t *testing.T) {
+		_, err := parseWindow("2024-01-01", "", 30, now)
+		assert.Error(t, err)
+		_, err = parseWindow("2024/01/01", "", 0, now)
+		assert.Error(t, err)
+		_, err = parseWindow("2025-01-01", "2024-12-31", 0, now)
+		assert.Error(t, err)
+	})
+}
+
+func Test_filterReplies(t *testing.T) {
+	msg := func(ts string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Timestamp: ts, ThreadTimestamp: "1600000000.000100"}}
+	}
+	replies := []slack.Message{
+		msg("1600000000.000100"), // 스레드 본문
+		msg("1600000500.000100"), // 기간 이전 답글
+		msg("1674000100.000100"),
+		msg("1674000200.000100"),
+		msg("1674009999.000100"), // 기간 이후 답글
+	}
+
+	got := filterReplies(replies, "1600000000.000100", "1674000000", "1674001000")
+
+	assert.Equal(t, []slack.Message{
+		msg("1674000100.000100"),
+		msg("1674000200.000100"),
+	}, got)
+}
+
+func Test_downloader_downloadChannel_resume(t *testing.T) {
+	var historyOldest []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conversations.history":
+			historyOldest = append(historyOldest, r.FormValue("oldest"))
+			if r.FormValue("oldest") == "1675000000" {
+				_, _ = w.Write([]byte(`{"ok":true,"messages":[{"ts":"1675100000.000100","text":":tada:"}]}`))
+				return
+			}
+			// 이어받기 전에 시작된 스레드에 새 답글이 달림
+			_, _ = w.Write([]byte(`{"ok":true,"messages":[{"ts":"1674950000.000100","text":"thread","reply_count":1,"latest_reply":"1675200000.000100"}]}`))
+		case "/conversations.replies":
+			_, _ = w.Write([]byte(`{"ok":true,"messages":[
+				{"ts":"1674950000.000100","text":"thread"},
+				{"ts":"1675200000.000100","thread_ts":"1674950000.000100","text":":party:"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	newDownloader := func(threadLookback int) *downloader {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, dataset.MessagesDirName), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, dataset.MessagesDirName, "general.json"), []byte(`[{"ts":"1674500000.000100","text":"stored"}]`), 0644))
+		return &downloader{
+			client:         slack.New("xoxp-token", slack.OptionAPIURL(srv.URL+"/")),
+			limiter:        newRateLimiter(),
+			window:         dataset.Window{Oldest: time.Unix(1674000000, 0), Latest: time.Unix(1676000000, 0)},
+			threadLookback: threadLookback,
+			dataset:        dir,
+			manifest: &dataset.Manifest{
+				Sources:  dataset.NewSources([]string{publicChannel}),
+				Channels: []dataset.Channel{{ID: "C1", Name: "general", Oldest: "1674000000", Latest: "1675000000"}},
+			},
+		}
+	}
+	channel := slack.Channel{}
+	channel.ID = "C1"
+	channel.Name = "general"
+	ctx := context.Background()
+
+	// 저장된 기간은 다시 훑지 않고 마지막 동기화 이후만 받음
+	d := newDownloader(0)
+	require.NoError(t, d.downloadChannel(ctx, channel))
+	assert.Equal(t, []string{"1675000000"}, historyOldest)
+	cp, _ := d.checkpoint("C1")
+	assert.Equal(t, "1676000000", cp.Latest)
+	assert.Equal(t, 2, cp.Messages)
+
+	// -thread-lookback만큼만 거슬러 올라가 새 답글이 달린 스레드를 찾음
+	historyOldest = nil
+	d = newDownloader(1)
+	require.NoError(t, d.downloadChannel(ctx, channel))
+	assert.Equal(t, []string{"1675000000", "1674913600"}, historyOldest)
+	cp, _ = d.checkpoint("C1")
+	assert.Equal(t, 3, cp.Messages)
+}
@@ -0,0 +1,138 @@
+package download
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// 봇 알림, 배포 로그처럼 메시지가 너무 많아 결과를 왜곡하는 채널을 거르기 위한 규칙
+// include/exclude의 각 규칙은 "id:C0123", "re:^alert-" 형태이거나 아무것도 붙지 않았다면 채널 이름 glob
+type channelFilter struct {
+	// 하나라도 맞는 채널만 받음. 비어있으면 모두 받음
+	Include []string `json:"include"`
+	// 하나라도 맞는 채널은 받지 않음. include보다 우선함
+	Exclude []string `json:"exclude"`
+	// 멤버 수가 이보다 적은 채널은 받지 않음. DM과 그룹 DM은 conversations.list가 멤버 수를 주지 않아 적용하지 않음
+	MinMembers int `json:"min_members"`
+	// 이 날짜(YYYY-MM-DD) 이전에 만들어진 채널만 받음
+	CreatedBefore string `json:"created_before"`
+
+	include       []channelMatcher
+	exclude       []channelMatcher
+	createdBefore time.Time
+}
+
+type channelMatcher func(ch slack.Channel) bool
+
+func loadChannelFilter(path string) (*channelFilter, error) {
+	f := &channelFilter{}
+	if path == "" {
+		return f, nil
+	}
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bb, f); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return f, nil
+}
+
+// 규칙을 미리 컴파일해두고 잘못된 규칙이 있다면 에러를 반환
+func (f *channelFilter) compile() error {
+	var err error
+	if f.include, err = compileChannelRules(f.Include); err != nil {
+		return errors.Wrap(err, "include")
+	}
+	if f.exclude, err = compileChannelRules(f.Exclude); err != nil {
+		return errors.Wrap(err, "exclude")
+	}
+	if f.MinMembers < 0 {
+		return errors.Errorf("min_members must be positive: %d", f.MinMembers)
+	}
+	if f.CreatedBefore != "" {
+		if f.createdBefore, err = time.ParseInLocation(dateLayout, f.CreatedBefore, time.Local); err != nil {
+			return errors.Wrap(err, "created_before")
+		}
+	}
+	return nil
+}
+
+func compileChannelRules(rules []string) ([]channelMatcher, error) {
+	matchers := make([]channelMatcher, 0, len(rules))
+	for _, rule := range rules {
+		switch {
+		case strings.HasPrefix(rule, "id:"):
+			id := strings.TrimPrefix(rule, "id:")
+			matchers = append(matchers, func(ch slack.Channel) bool {
+				return ch.ID == id
+			})
+		case strings.HasPrefix(rule, "re:"):
+			re, err := regexp.Compile(strings.TrimPrefix(rule, "re:"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "'%s'", rule)
+			}
+			matchers = append(matchers, func(ch slack.Channel) bool {
+				return re.MatchString(channelName(ch))
+			})
+		default:
+			glob := strings.TrimPrefix(strings.TrimPrefix(rule, "glob:"), "#")
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, errors.Wrapf(err, "'%s'", rule)
+			}
+			matchers = append(matchers, func(ch slack.Channel) bool {
+				ok, _ := path.Match(glob, channelName(ch))
+				return ok
+			})
+		}
+	}
+	return matchers, nil
+}
+
+// 받을 채널이면 true
+func (f *channelFilter) match(ch slack.Channel) bool {
+	for _, m := range f.exclude {
+		if m(ch) {
+			return false
+		}
+	}
+	if len(f.include) > 0 && !matchAny(f.include, ch) {
+		return false
+	}
+	if !ch.IsIM && !ch.IsMpIM && ch.NumMembers < f.MinMembers {
+		return false
+	}
+	if !f.createdBefore.IsZero() && !ch.Created.Time().Before(f.createdBefore) {
+		return false
+	}
+	return true
+}
+
+func matchAny(matchers []channelMatcher, ch slack.Channel) bool {
+	for _, m := range matchers {
+		if m(ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// -include, -exclude처럼 여러 번 지정할 수 있는 플래그
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
@@ -0,0 +1,55 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_channelFilter_match(t *testing.T) {
+	channel := func(id, name string, members int, created time.Time) slack.Channel {
+		ch := slack.Channel{}
+		ch.ID = id
+		ch.Name = name
+		ch.NumMembers = members
+		ch.Created = slack.JSONTime(created.Unix())
+		return ch
+	}
+	old := time.Date(2019, 1, 1, 0, 0, 0, 0, time.Local)
+	channels := []slack.Channel{
+		channel("C1", "general", 300, old),
+		channel("C2", "alert-prod", 40, old),
+		channel("C3", "deploy-log", 40, old),
+		channel("C4", "team-dev", 12, old),
+		channel("C5", "team-design", 2, old),
+		channel("C6", "team-new", 12, time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)),
+		// 그룹 DM은 멤버 수가 0으로 와서 -min-members를 적용하지 않음
+		channel("G1", "mpdm-alice--bob-1", 0, old),
+	}
+	channels[6].IsMpIM = true
+
+	f := &channelFilter{
+		Include:       []string{"general", "team-*", "re:^alert-", "mpdm-*"},
+		Exclude:       []string{"id:C2"},
+		MinMembers:    10,
+		CreatedBefore: "2024-01-01",
+	}
+	assert.NoError(t, f.compile())
+
+	got := make([]string, 0)
+	for _, ch := range channels {
+		if f.match(ch) {
+			got = append(got, ch.Name)
+		}
+	}
+	assert.Equal(t, []string{"general", "team-dev", "mpdm-alice--bob-1"}, got)
+}
+
+func Test_channelFilter_compile(t *testing.T) {
+	assert.Error(t, (&channelFilter{Exclude: []string{"re:("}}).compile())
+	assert.Error(t, (&channelFilter{Include: []string{"[a-"}}).compile())
+	assert.Error(t, (&channelFilter{CreatedBefore: "yesterday"}).compile())
+	assert.NoError(t, (&channelFilter{}).compile())
+}
@@ -0,0 +1,102 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+const adminEmojiListLimit = 1000
+
+// emoji.list는 이름과 URL만 주기에 언제 누가 만들었는지는 admin.emoji.list로 따로 받음
+// stale이 만든 지 얼마 안 된 이모지를 판단에서 빼는데 씀
+type emojiMetadata struct {
+	CreatedAt  time.Time `json:"created_at"`
+	UploadedBy string    `json:"uploaded_by,omitempty"`
+}
+
+type adminEmojiListResponse struct {
+	slack.SlackResponse
+	Emoji map[string]struct {
+		URL         string `json:"url"`
+		DateCreated int64  `json:"date_created"`
+		UploadedBy  string `json:"uploaded_by"`
+	} `json:"emoji"`
+}
+
+// admin.emoji.list는 Enterprise Grid에서 admin.teams:read scope가 있는 토큰으로만 호출할 수 있음
+func (d *downloader) saveEmojiMetadata(ctx context.Context, token string) error {
+	metadata := make(map[string]emojiMetadata)
+	var cursor string
+	for {
+		var resp adminEmojiListResponse
+		err := d.limiter.call(ctx, "admin.emoji.list", func() (err error) {
+			resp, err = fetchAdminEmojiList(ctx, slack.APIURL, token, cursor)
+			return err
+		})
+		if err != nil {
+			return errors.Wrap(err, "admin.emoji.list")
+		}
+		for name, e := range resp.Emoji {
+			metadata[mrkdwn.NormalizeName(name)] = emojiMetadata{
+				CreatedAt:  time.Unix(e.DateCreated, 0),
+				UploadedBy: e.UploadedBy,
+			}
+		}
+		cursor = resp.ResponseMetadata.Cursor
+		if cursor == "" {
+			break
+		}
+	}
+	log.Infof("metadata of %d emojis are downloaded", len(metadata))
+	return dataset.SaveJSON(filepath.Join(d.dataset, dataset.EmojiMetadataJSONName), metadata)
+}
+
+// slack-go에는 admin.emoji.list가 없어 직접 호출함
+func fetchAdminEmojiList(ctx context.Context, apiURL, token, cursor string) (adminEmojiListResponse, error) {
+	form := url.Values{
+		"limit": {strconv.Itoa(adminEmojiListLimit)},
+	}
+	if cursor != "" {
+		form.Set("cursor", cursor)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"admin.emoji.list?"+form.Encode(), nil)
+	if err != nil {
+		return adminEmojiListResponse{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return adminEmojiListResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	// limiter가 기다렸다 다시 시도할 수 있도록 slack-go와 같은 에러로 돌려줌
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
+		if err != nil {
+			retryAfter = 60
+		}
+		return adminEmojiListResponse{}, &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+
+	var list adminEmojiListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return adminEmojiListResponse{}, err
+	}
+	if err := list.Err(); err != nil {
+		return adminEmojiListResponse{}, err
+	}
+	return list, nil
+}
@@ -0,0 +1,54 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fetchAdminEmojiList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin.emoji.list", r.URL.Path)
+		assert.Equal(t, "Bearer xoxp-token", r.Header.Get("Authorization"))
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"ok":true,"emoji":{"party":{"url":"https://link/party.png","date_created":1700000000,"uploaded_by":"U1"}},"response_metadata":{"next_cursor":"next"}}`))
+		case "next":
+			_, _ = w.Write([]byte(`{"ok":true,"emoji":{"tada":{"url":"https://link/tada.png","date_created":1700000100,"uploaded_by":"U2"}},"response_metadata":{"next_cursor":""}}`))
+		case "limited":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"ok":false,"error":"not_allowed_token_type"}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	resp, err := fetchAdminEmojiList(ctx, srv.URL+"/", "xoxp-token", "")
+	require.NoError(t, err)
+	assert.Equal(t, "next", resp.ResponseMetadata.Cursor)
+	assert.Equal(t, int64(1700000000), resp.Emoji["party"].DateCreated)
+	assert.Equal(t, "U1", resp.Emoji["party"].UploadedBy)
+
+	resp, err = fetchAdminEmojiList(ctx, srv.URL+"/", "xoxp-token", "next")
+	require.NoError(t, err)
+	assert.Equal(t, "", resp.ResponseMetadata.Cursor)
+	assert.Contains(t, resp.Emoji, "tada")
+
+	// limiter가 다시 시도할 수 있도록 RateLimitedError를 반환
+	_, err = fetchAdminEmojiList(ctx, srv.URL+"/", "xoxp-token", "limited")
+	var rateLimitedError *slack.RateLimitedError
+	require.True(t, errors.As(err, &rateLimitedError))
+	assert.Equal(t, 3*time.Second, rateLimitedError.RetryAfter)
+
+	_, err = fetchAdminEmojiList(ctx, srv.URL+"/", "xoxp-token", "unknown")
+	assert.EqualError(t, err, "not_allowed_token_type")
+}
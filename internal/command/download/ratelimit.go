@@ -0,0 +1,148 @@
+package download
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+// 슬랙 API는 메서드마다 tier가 정해져 있고 tier별로 분당 호출 횟수가 제한됨
+// https://api.slack.com/docs/rate-limits
+var (
+	methodTiers = map[string]int{
+		"auth.test":             4,
+		"emoji.list":            2,
+		"admin.emoji.list":      2,
+		"users.list":            2,
+		"conversations.list":    2,
+		"conversations.history": 3,
+		"conversations.replies": 3,
+		"bookmarks.list":        3,
+	}
+	tierRequestsPerMinute = map[int]int{
+		1: 1,
+		2: 20,
+		3: 50,
+		4: 100,
+	}
+)
+
+// 메서드별 토큰 버킷. 여러 워커가 같은 limiter를 공유해 전체 호출 속도를 맞춤
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	interval    time.Duration // 토큰 하나가 채워지는 간격
+	capacity    float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// 모르는 메서드는 보수적으로 tier 2로 취급
+func newBucket(method string, now time.Time) *bucket {
+	tier, ok := methodTiers[method]
+	if !ok {
+		tier = 2
+	}
+	perMinute := tierRequestsPerMinute[tier]
+	// 슬랙은 잠깐의 burst는 허용해주기에 분당 허용량의 1/10 정도는 한 번에 쓸 수 있게 함
+	capacity := math.Max(1, float64(perMinute/10))
+	return &bucket{
+		interval: time.Minute / time.Duration(perMinute),
+		capacity: capacity,
+		tokens:   capacity,
+		last:     now,
+	}
+}
+
+// 토큰을 얻을 때까지 대기. ctx가 취소되면 바로 반환
+func (l *rateLimiter) wait(ctx context.Context, method string) error {
+	for {
+		delay := l.reserve(method, time.Now())
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// 토큰을 하나 가져가면 0을, 아니면 다시 시도하기까지 기다려야 하는 시간을 반환
+func (l *rateLimiter) reserve(method string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[method]
+	if !ok {
+		b = newBucket(method, now)
+		l.buckets[method] = b
+	}
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+float64(elapsed)/float64(b.interval))
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
+
+// rate limit에 걸렸다면 다른 워커도 같은 메서드를 RetryAfter 동안 호출하지 않도록 멈춰둠
+func (l *rateLimiter) pause(method string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[method]
+	if !ok {
+		b = newBucket(method, now)
+		l.buckets[method] = b
+	}
+	until := now.Add(d)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		b.last = until
+	}
+	b.tokens = 0
+}
+
+// 토큰을 얻은 뒤 fn을 호출하고, 그래도 rate limit에 걸리면 RetryAfter만큼 기다렸다 다시 시도
+func (l *rateLimiter) call(ctx context.Context, method string, fn func() error) error {
+	for {
+		if err := l.wait(ctx, method); err != nil {
+			return err
+		}
+		err := fn()
+		var rateLimitedError *slack.RateLimitedError
+		if errors.As(err, &rateLimitedError) {
+			log.WithField("method", method).Warnf("rate limited, retry after %s", rateLimitedError.RetryAfter)
+			l.pause(method, rateLimitedError.RetryAfter)
+			continue
+		}
+		return err
+	}
+}
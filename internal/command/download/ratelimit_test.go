@@ -0,0 +1,30 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rateLimiter_reserve(t *testing.T) {
+	l := newRateLimiter()
+	now := time.Now()
+
+	// tier 2는 분당 20번이라 한 번에 2개까지 쓸 수 있고 이후엔 3초마다 하나씩 채워짐
+	assert.Equal(t, time.Duration(0), l.reserve("emoji.list", now))
+	assert.Equal(t, time.Duration(0), l.reserve("emoji.list", now))
+	assert.Equal(t, 3*time.Second, l.reserve("emoji.list", now))
+	assert.Equal(t, time.Duration(0), l.reserve("emoji.list", now.Add(3*time.Second)))
+
+	// 메서드마다 버킷이 따로 있음
+	assert.Equal(t, time.Duration(0), l.reserve("conversations.history", now))
+}
+
+func Test_rateLimiter_pause(t *testing.T) {
+	l := newRateLimiter()
+	l.pause("conversations.history", time.Minute)
+
+	delay := l.reserve("conversations.history", time.Now())
+	assert.Greater(t, delay, 50*time.Second)
+}
@@ -0,0 +1,192 @@
+package download
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/dataset"
+)
+
+// 메시지 밖에서 이모지가 쓰이는 곳을 받는데 필요한 scope. 없으면 경고만 하고 건너뜀
+// 채널 토픽과 목적은 채널 목록과 같이 받으니 따로 필요한 scope가 없음
+var surfaceScopes = map[string]string{
+	dataset.UsersJSONName:     "users:read",
+	dataset.BookmarksJSONName: "bookmarks:read",
+}
+
+// 상태 이모지를 지우면 상태에서도 사라지기에 쓰고 있는 사람이 있다면 사용 중인 것으로 봄
+type userStatus struct {
+	ID          string `json:"id"`
+	StatusEmoji string `json:"status_emoji"`
+	StatusText  string `json:"status_text,omitempty"`
+}
+
+type channelBookmark struct {
+	ChannelID string `json:"channel_id"`
+	Title     string `json:"title"`
+	Emoji     string `json:"emoji,omitempty"`
+}
+
+// channels.json은 한 번 받으면 계속 쓰는 캐시라 토픽과 목적은 매번 새로 받아 따로 저장함
+type channelTopic struct {
+	ChannelID      string `json:"channel_id"`
+	Topic          string `json:"topic,omitempty"`
+	TopicCreator   string `json:"topic_creator,omitempty"`
+	Purpose        string `json:"purpose,omitempty"`
+	PurposeCreator string `json:"purpose_creator,omitempty"`
+}
+
+func hasScope(scopes []string, scope string) bool {
+	// 헤더를 못 받은 경우라면 일단 시도해봄
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func isMissingScope(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return slackErr.Err == "missing_scope"
+	}
+	return err != nil && strings.Contains(err.Error(), "missing_scope")
+}
+
+// 상태 이모지나 상태 메시지에 이모지를 쓰는 사람만 저장함
+func (d *downloader) saveUserStatuses(ctx context.Context) error {
+	var users []slack.User
+	err := d.limiter.call(ctx, "users.list", func() (err error) {
+		users, err = d.client.GetUsersContext(ctx)
+		return err
+	})
+	if isMissingScope(err) {
+		log.Warnf("missing_scope: add %s to User Token Scopes to count status emojis", surfaceScopes[dataset.UsersJSONName])
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "GetUsers")
+	}
+
+	statuses := make([]userStatus, 0)
+	for _, u := range users {
+		if u.Deleted || u.IsBot {
+			continue
+		}
+		if u.Profile.StatusEmoji == "" && !strings.Contains(u.Profile.StatusText, ":") {
+			continue
+		}
+		statuses = append(statuses, userStatus{
+			ID:          u.ID,
+			StatusEmoji: u.Profile.StatusEmoji,
+			StatusText:  u.Profile.StatusText,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	log.Infof("%d users have a status", len(statuses))
+	return dataset.SaveJSON(filepath.Join(d.dataset, dataset.UsersJSONName), statuses)
+}
+
+// 채널 북마크를 불러와 모아둠. 권한이 없다면 처음 한 번만 경고하고 이후로는 부르지 않음
+func (d *downloader) collectBookmarks(ctx context.Context, channel slack.Channel) error {
+	d.mu.Lock()
+	enabled := d.bookmarks != nil
+	d.mu.Unlock()
+	if !enabled {
+		return nil
+	}
+
+	var bookmarks []slack.Bookmark
+	err := d.limiter.call(ctx, "bookmarks.list", func() (err error) {
+		bookmarks, err = d.client.ListBookmarksContext(ctx, channel.ID)
+		return err
+	})
+	if isMissingScope(err) {
+		d.mu.Lock()
+		if d.bookmarks != nil {
+			log.Warnf("missing_scope: add %s to User Token Scopes to count bookmark emojis", surfaceScopes[dataset.BookmarksJSONName])
+			d.bookmarks = nil
+		}
+		d.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "bookmarks of %s", channelName(channel))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.bookmarks == nil {
+		return nil
+	}
+	for _, b := range bookmarks {
+		d.bookmarks[channel.ID] = append(d.bookmarks[channel.ID], channelBookmark{
+			ChannelID: channel.ID,
+			Title:     b.Title,
+			Emoji:     b.Emoji,
+		})
+	}
+	return nil
+}
+
+func (d *downloader) saveBookmarks() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.bookmarks == nil {
+		return nil
+	}
+	bookmarks := make([]channelBookmark, 0)
+	for _, bb := range d.bookmarks {
+		bookmarks = append(bookmarks, bb...)
+	}
+	sort.SliceStable(bookmarks, func(i, j int) bool {
+		return bookmarks[i].ChannelID < bookmarks[j].ChannelID
+	})
+	log.Infof("%d bookmarks are collected", len(bookmarks))
+	return dataset.SaveJSON(filepath.Join(d.dataset, dataset.BookmarksJSONName), bookmarks)
+}
+
+// 받을 채널의 지금 토픽과 목적을 저장함. channels는 필터를 거친 채널이라 필터에서 빠진 채널의 토픽은 세지 않음
+func (d *downloader) saveTopics(ctx context.Context, channels []slack.Channel) error {
+	listed, err := d.listChannels(ctx)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]slack.Channel, len(listed))
+	for _, ch := range listed {
+		current[ch.ID] = ch
+	}
+
+	topics := make([]channelTopic, 0)
+	for _, ch := range channels {
+		// 목록에 없다면 캐시를 받은 뒤로 아카이브된 채널
+		ch, ok := current[ch.ID]
+		if !ok || (ch.Topic.Value == "" && ch.Purpose.Value == "") {
+			continue
+		}
+		topics = append(topics, channelTopic{
+			ChannelID:      ch.ID,
+			Topic:          ch.Topic.Value,
+			TopicCreator:   ch.Topic.Creator,
+			Purpose:        ch.Purpose.Value,
+			PurposeCreator: ch.Purpose.Creator,
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].ChannelID < topics[j].ChannelID
+	})
+	log.Infof("%d channels have a topic or purpose", len(topics))
+	return dataset.SaveJSON(filepath.Join(d.dataset, dataset.TopicsJSONName), topics)
+}
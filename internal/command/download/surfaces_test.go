@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_downloader_surfaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users.list":
+			_, _ = w.Write([]byte(`{"ok":true,"members":[
+				{"id":"U2","profile":{"status_emoji":":palm_tree:","status_text":"휴가"}},
+				{"id":"U1","profile":{"status_emoji":"","status_text":"회의 중 :calendar:"}},
+				{"id":"U3","profile":{"status_emoji":"","status_text":"그냥 텍스트"}},
+				{"id":"U4","deleted":true,"profile":{"status_emoji":":ghost:"}},
+				{"id":"B1","is_bot":true,"profile":{"status_emoji":":robot_face:"}}
+			]}`))
+		case "/bookmarks.list":
+			if r.FormValue("channel_id") == "C2" {
+				_, _ = w.Write([]byte(`{"ok":false,"error":"missing_scope"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"bookmarks":[{"id":"Bk1","channel_id":"C1","title":"위키","emoji":":book:"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	channel := func(id string) slack.Channel {
+		ch := slack.Channel{}
+		ch.ID = id
+		return ch
+	}
+	d := &downloader{
+		client:    slack.New("xoxp-token", slack.OptionAPIURL(srv.URL+"/")),
+		limiter:   newRateLimiter(),
+		dataset:   t.TempDir(),
+		bookmarks: make(map[string][]channelBookmark),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, d.saveUserStatuses(ctx))
+	var statuses []userStatus
+	readJSON(t, filepath.Join(d.dataset, dataset.UsersJSONName), &statuses)
+	assert.Equal(t, []userStatus{
+		{ID: "U1", StatusText: "회의 중 :calendar:"},
+		{ID: "U2", StatusEmoji: ":palm_tree:", StatusText: "휴가"},
+	}, statuses)
+
+	require.NoError(t, d.collectBookmarks(ctx, channel("C1")))
+	require.NoError(t, d.saveBookmarks())
+	var bookmarks []channelBookmark
+	readJSON(t, filepath.Join(d.dataset, dataset.BookmarksJSONName), &bookmarks)
+	assert.Equal(t, []channelBookmark{{ChannelID: "C1", Title: "위키", Emoji: ":book:"}}, bookmarks)
+
+	// 권한이 없으면 경고만 하고 더 이상 북마크를 받지 않음
+	require.NoError(t, d.collectBookmarks(ctx, channel("C2")))
+	assert.Nil(t, d.bookmarks)
+	require.NoError(t, d.collectBookmarks(ctx, channel("C1")))
+	assert.Nil(t, d.bookmarks)
+}
+
+func Test_downloader_saveTopics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/conversations.list" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"channels":[
+			{"id":"C1","name":"general","topic":{"value":"배포 :rocket:","creator":"U2"},"purpose":{"value":":wave: 인사","creator":"U3"}},
+			{"id":"C2","name":"random","topic":{"value":":ghost:","creator":"U2"}},
+			{"id":"C3","name":"empty"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	channel := func(id, topic string) slack.Channel {
+		ch := slack.Channel{}
+		ch.ID = id
+		ch.Topic.Value = topic
+		return ch
+	}
+	d := &downloader{
+		client:  slack.New("xoxp-token", slack.OptionAPIURL(srv.URL+"/")),
+		limiter: newRateLimiter(),
+		types:   []string{publicChannel},
+		dataset: t.TempDir(),
+	}
+
+	// C2는 필터에서 빠졌고 C4는 캐시를 받은 뒤로 아카이브돼서 목록에 없음
+	// 캐시에 남은 예전 토픽 대신 지금 토픽을 저장함
+	require.NoError(t, d.saveTopics(context.Background(), []slack.Channel{
+		channel("C1", ":old:"),
+		channel("C3", ""),
+		channel("C4", ":archived:"),
+	}))
+	var topics []channelTopic
+	readJSON(t, filepath.Join(d.dataset, dataset.TopicsJSONName), &topics)
+	assert.Equal(t, []channelTopic{
+		{ChannelID: "C1", Topic: "배포 :rocket:", TopicCreator: "U2", Purpose: ":wave: 인사", PurposeCreator: "U3"},
+	}, topics)
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	bb, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bb, v))
+}
@@ -0,0 +1,123 @@
+package duplicate
+
+import (
+	"sort"
+
+	"emojicleaner/internal/unionfind"
+)
+
+type archivedImage struct {
+	Name   string
+	SHA256 string
+	// 이미지를 읽지 못했다면 hashed가 false이고 같은 내용인지만 비교함
+	Hash   uint64
+	hashed bool
+}
+
+type member struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// 남길 이모지와 이미지 파일이 똑같은지
+	Exact bool `json:"exact"`
+	// 남길 이모지와 dHash의 해밍 거리
+	Distance int `json:"distance"`
+	// 보호 목록의 어떤 규칙으로 보호되는지. 보호하는 이모지는 alias로 바꾸자고 제안하지 않음
+	ProtectedBy string `json:"protected_by,omitempty"`
+}
+
+// 비슷한 이미지의 이모지 묶음. Keep을 남기고 Replace는 지운 뒤 Keep의 alias로 바꾸는 것을 제안함
+type cluster struct {
+	Keep    string   `json:"keep"`
+	Replace []string `json:"replace"`
+	Emojis  []member `json:"emojis"`
+}
+
+// 내용이 같은 이미지와 dHash의 해밍 거리가 threshold 이하인 이미지를 한 묶음으로 만듦
+// 거리는 전이되지 않지만 A~B, B~C라면 A, B, C를 같은 묶음으로 봄
+// protected는 보호하는 이모지 이름 → 규칙
+func clusterImages(images []archivedImage, counts map[string]int, protected map[string]string, threshold int) []cluster {
+	u := unionfind.New(len(images))
+	bySHA := make(map[string]int)
+	for i, img := range images {
+		if j, ok := bySHA[img.SHA256]; ok {
+			u.Union(i, j)
+			continue
+		}
+		bySHA[img.SHA256] = i
+	}
+	for i := range images {
+		// 단색 이미지는 모두 0이 되어 서로 다른 이모지가 같은 묶음이 되니 비교하지 않음
+		if !images[i].hashed || images[i].Hash == 0 {
+			continue
+		}
+		for j := i + 1; j < len(images); j++ {
+			if !images[j].hashed || images[j].Hash == 0 {
+				continue
+			}
+			if hammingDistance(images[i].Hash, images[j].Hash) <= threshold {
+				u.Union(i, j)
+			}
+		}
+	}
+
+	clusters := make([]cluster, 0)
+	for _, members := range u.Groups() {
+		if len(members) < 2 {
+			continue
+		}
+		group := make([]archivedImage, len(members))
+		for k, i := range members {
+			group[k] = images[i]
+		}
+		// 보호하는 이모지만 남아 바꿀 이모지가 없는 묶음은 뺌
+		if c := newCluster(group, counts, protected); len(c.Replace) > 0 {
+			clusters = append(clusters, c)
+		}
+	}
+	// 묶음이 큰 것부터
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Emojis) != len(clusters[j].Emojis) {
+			return len(clusters[i].Emojis) > len(clusters[j].Emojis)
+		}
+		return clusters[i].Keep < clusters[j].Keep
+	})
+	return clusters
+}
+
+// 보호하는 이모지는 어차피 지울 수 없으니 먼저 남기고, 그 다음은 가장 많이 쓰인 이모지를 남김. 같다면 이름이 짧은 것, 그 다음은 이름순
+// 보호하는 이모지가 여럿이라면 남기는 이모지가 아니더라도 Replace에 넣지 않음
+func newCluster(group []archivedImage, counts map[string]int, protected map[string]string) cluster {
+	sort.Slice(group, func(i, j int) bool {
+		a, b := group[i], group[j]
+		_, pa := protected[a.Name]
+		_, pb := protected[b.Name]
+		if pa != pb {
+			return pa
+		}
+		if counts[a.Name] != counts[b.Name] {
+			return counts[a.Name] > counts[b.Name]
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return a.Name < b.Name
+	})
+	keep := group[0]
+	c := cluster{Keep: keep.Name, Replace: make([]string, 0, len(group)-1)}
+	for i, img := range group {
+		m := member{
+			Name:        img.Name,
+			Count:       counts[img.Name],
+			Exact:       img.SHA256 == keep.SHA256,
+			ProtectedBy: protected[img.Name],
+		}
+		if !m.Exact && img.hashed && keep.hashed {
+			m.Distance = hammingDistance(img.Hash, keep.Hash)
+		}
+		c.Emojis = append(c.Emojis, m)
+		if i > 0 && m.ProtectedBy == "" {
+			c.Replace = append(c.Replace, img.Name)
+		}
+	}
+	return c
+}
@@ -0,0 +1,79 @@
+package duplicate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_clusterImages(t *testing.T) {
+	images := []archivedImage{
+		{Name: "party-parrot", SHA256: "a", Hash: 0xF0F0, hashed: true},
+		{Name: "partyparrot2", SHA256: "a", Hash: 0xF0F0, hashed: true},
+		// 한 비트만 다른 비슷한 이미지
+		{Name: "party_parrot_hd", SHA256: "b", Hash: 0xF0F1, hashed: true},
+		{Name: "cat", SHA256: "c", Hash: 0x0F0F, hashed: true},
+		// 읽지 못한 이미지는 내용이 같을 때만 묶음
+		{Name: "webp1", SHA256: "d"},
+		{Name: "webp2", SHA256: "d"},
+		{Name: "webp3", SHA256: "e"},
+		// 단색 이미지는 비교하지 않음
+		{Name: "white", SHA256: "f", hashed: true},
+		{Name: "black", SHA256: "g", hashed: true},
+	}
+	counts := map[string]int{"partyparrot2": 30, "party-parrot": 3}
+
+	got := clusterImages(images, counts, nil, 2)
+
+	assert.Equal(t, []cluster{
+		{
+			Keep:    "partyparrot2",
+			Replace: []string{"party-parrot", "party_parrot_hd"},
+			Emojis: []member{
+				{Name: "partyparrot2", Count: 30, Exact: true},
+				{Name: "party-parrot", Count: 3, Exact: true},
+				{Name: "party_parrot_hd", Distance: 1},
+			},
+		},
+		{
+			Keep:    "webp1",
+			Replace: []string{"webp2"},
+			Emojis:  []member{{Name: "webp1", Exact: true}, {Name: "webp2", Exact: true}},
+		},
+	}, got)
+
+	// 거리가 0이면 똑같은 해시만 묶음
+	got = clusterImages(images, counts, nil, 0)
+	assert.Len(t, got, 2)
+	assert.Equal(t, []string{"party-parrot"}, got[0].Replace)
+}
+
+func Test_clusterImages_protected(t *testing.T) {
+	images := []archivedImage{
+		{Name: "party-parrot", SHA256: "a", Hash: 0xF0F0, hashed: true},
+		{Name: "partyparrot2", SHA256: "a", Hash: 0xF0F0, hashed: true},
+		{Name: "party_parrot_hd", SHA256: "b", Hash: 0xF0F1, hashed: true},
+		{Name: "webp1", SHA256: "d"},
+		{Name: "webp2", SHA256: "d"},
+	}
+	counts := map[string]int{"partyparrot2": 30, "party-parrot": 3}
+	protected := map[string]string{
+		"party-parrot": "name:party-parrot",
+		"webp1":        "glob:webp*",
+		"webp2":        "glob:webp*",
+	}
+
+	// 덜 쓰였더라도 보호하는 이모지를 남기고, 모두 보호하는 묶음은 바꿀 게 없으니 뺌
+	got := clusterImages(images, counts, protected, 2)
+	assert.Equal(t, []cluster{
+		{
+			Keep:    "party-parrot",
+			Replace: []string{"partyparrot2", "party_parrot_hd"},
+			Emojis: []member{
+				{Name: "party-parrot", Count: 3, Exact: true, ProtectedBy: "name:party-parrot"},
+				{Name: "partyparrot2", Count: 30, Exact: true},
+				{Name: "party_parrot_hd", Distance: 1},
+			},
+		},
+	}, got)
+}
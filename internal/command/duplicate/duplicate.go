@@ -0,0 +1,173 @@
+package duplicate
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/archive"
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/protect"
+)
+
+// 이미지가 같거나 비슷한 이모지를 묶음
+var Command = &cli.Command{
+	Name:  "duplicate",
+	Short: "group custom emojis with identical or similar images in the archive",
+	Setup: setup,
+}
+
+func setup(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+	archiveDir := fs.String("archive", "", "emoji archive directory made by download -archive (required)")
+	usage := fs.String("usage", "", "all_emojis.json made by stale, used to keep the most used emoji of each cluster (default <output-dir>/all_emojis.json, or .jsonl with -format jsonl)")
+	threshold := fs.Int("threshold", 6, "max hamming distance of 64 bit dHash to treat images as near duplicates, 0 for identical hashes only")
+	output := fs.String("output", "duplicate_emojis.json", "file name in -output-dir to save clusters of duplicate emojis")
+	protectPath := fs.String("protect", "", "json file listing emojis never to propose replacing with an alias")
+
+	return func(ctx context.Context) error {
+		if *archiveDir == "" {
+			return cli.UsageErrorf("-archive is required, download it first with download -archive")
+		}
+		if *threshold < 0 || *threshold > 64 {
+			return cli.UsageErrorf("-threshold must be between 0 and 64, got %d", *threshold)
+		}
+		return duplicate(g, *archiveDir, *usage, *protectPath, *output, *threshold)
+	}
+}
+
+func duplicate(g *cli.Globals, archiveDir, usagePath, protectPath, output string, threshold int) error {
+	emojis, err := dataset.LoadEmojis(g.Dataset)
+	if err != nil {
+		return err
+	}
+	protection, err := protect.Load(protectPath)
+	if err != nil {
+		return err
+	}
+	protected := protectedEmojis(protection, emojis)
+	index, err := archive.Load(archiveDir)
+	if err != nil {
+		return err
+	}
+	counts, err := loadUsage(g, usagePath)
+	if err != nil {
+		return err
+	}
+
+	images := loadImages(archiveDir, index, customEmojiNames(emojis))
+	clusters := clusterImages(images, counts, protected, threshold)
+	var duplicates int
+	for _, c := range clusters {
+		duplicates += len(c.Replace)
+		log.Infof("keep :%s: and replace %s with aliases", c.Keep, strings.Join(c.Replace, ", "))
+	}
+	log.Infof("%d clusters are found, %d emojis can be replaced with aliases", len(clusters), duplicates)
+	return g.Save(output, clusters)
+}
+
+// 워크스페이스에 남아있는 커스텀 이모지 이름. alias는 이미지가 따로 없으니 뺌
+func customEmojiNames(emojis *dataset.Emojis) []string {
+	names := make([]string, 0, len(emojis.Links))
+	for name := range emojis.Links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 보호하는 원본 이모지 이름 → 규칙
+// alias로 바꾸려면 원본을 지워야 하고 그러면 그 alias도 같이 지워지니 보호하는 alias가 있는 원본도 보호함
+func protectedEmojis(p *protect.Rules, emojis *dataset.Emojis) map[string]string {
+	protected := make(map[string]string)
+	for name := range emojis.Links {
+		if reason, ok := p.Match(name, name); ok {
+			protected[name] = reason
+		}
+	}
+	aliases := make([]string, 0, len(emojis.Aliases))
+	for alias := range emojis.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		target := emojis.Aliases[alias]
+		if _, ok := emojis.Links[target]; !ok {
+			continue
+		}
+		if _, ok := protected[target]; ok {
+			continue
+		}
+		if reason, ok := p.Match(alias, target); ok {
+			protected[target] = reason
+		}
+	}
+	return protected
+}
+
+// stale의 결과가 없으면 모두 0번 쓰인 것으로 보고 이름으로만 남길 이모지를 고름
+// path가 비어있으면 결과 디렉토리에서 -format 형식으로 저장된 all_emojis.json을 읽음
+func loadUsage(g *cli.Globals, path string) (map[string]int, error) {
+	var emojis []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	var err error
+	if path == "" {
+		path = "all_emojis.json"
+		err = g.Load(path, &emojis)
+	} else {
+		err = cli.LoadFile(path, &emojis)
+	}
+	counts := make(map[string]int)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Warnf("'%s' is not found, run stale first to keep the most used emojis", path)
+		return counts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range emojis {
+		counts[e.Name] = e.Count
+	}
+	return counts, nil
+}
+
+// 같은 내용의 이미지는 한 번만 읽음. 읽을 수 없는 형식(webp 등)은 내용이 같은지만 비교함
+func loadImages(dir string, index *archive.Index, names []string) []archivedImage {
+	type hashed struct {
+		hash uint64
+		ok   bool
+	}
+	cache := make(map[string]hashed)
+	images := make([]archivedImage, 0, len(names))
+	var missing int
+	for _, name := range names {
+		e, ok := index.Emojis[name]
+		if !ok {
+			missing++
+			continue
+		}
+		h, ok := cache[e.SHA256]
+		if !ok {
+			hash, err := imageHash(filepath.Join(dir, filepath.FromSlash(e.Path)))
+			if err != nil {
+				log.WithError(err).Warnf("cannot read image of :%s:, only exact duplicates are found", name)
+			}
+			h = hashed{hash: hash, ok: err == nil}
+			cache[e.SHA256] = h
+		}
+		images = append(images, archivedImage{Name: name, SHA256: e.SHA256, Hash: h.hash, hashed: h.ok})
+	}
+	if missing > 0 {
+		log.Warnf("%d emojis are not archived, run download -archive first", missing)
+	}
+	log.Infof("%d images are loaded", len(images))
+	return images
+}
@@ -0,0 +1,33 @@
+package duplicate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/protect"
+)
+
+func Test_protectedEmojis(t *testing.T) {
+	p := &protect.Rules{Names: []string{"logo", "ceo-face"}}
+	require.NoError(t, p.Compile())
+	emojis := &dataset.Emojis{
+		Links: map[string]string{
+			"logo":   "https://emoji/logo.png",
+			"ceo":    "https://emoji/ceo.png",
+			"parrot": "https://emoji/parrot.png",
+		},
+		Aliases: map[string]string{
+			// 보호하는 alias의 원본을 지우면 alias도 같이 지워지니 원본도 보호함
+			"ceo-face": "ceo",
+			"parrot2":  "parrot",
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"logo": "name:logo",
+		"ceo":  "name:ceo-face",
+	}, protectedEmojis(p, emojis))
+}
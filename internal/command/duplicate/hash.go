@@ -0,0 +1,86 @@
+package duplicate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+	"os"
+)
+
+const (
+	// dHash는 (dhashSize+1)×dhashSize로 줄인 뒤 가로로 이웃한 픽셀의 밝기를 비교해 64비트를 만듦
+	dhashSize = 8
+)
+
+// 이미지를 읽어 dHash를 계산함. GIF는 첫 프레임만 봄
+func imageHash(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+	return dhash(img), nil
+}
+
+// 투명한 부분은 슬랙의 밝은 배경처럼 흰색으로 보고 계산함
+// 같은 이미지를 크기만 바꿔 올려도 같은 값이 나오도록 픽셀 영역의 평균 밝기로 줄임
+func dhash(img image.Image) uint64 {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Over)
+
+	w, h := dhashSize+1, dhashSize
+	gray := make([][]float64, h)
+	for y := 0; y < h; y++ {
+		gray[y] = make([]float64, w)
+		y0, y1 := span(y, h, b.Dy())
+		for x := 0; x < w; x++ {
+			x0, x1 := span(x, w, b.Dx())
+			var sum float64
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					c := rgba.RGBAAt(px, py)
+					sum += 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+				}
+			}
+			gray[y][x] = sum / float64((x1-x0)*(y1-y0))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < h; y++ {
+		for x := 0; x < dhashSize; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// n칸으로 나눈 길이 size 중 i번째 칸의 범위. 이미지가 칸보다 작아도 한 픽셀은 포함함
+func span(i, n, size int) (int, int) {
+	start := i * size / n
+	end := (i + 1) * size / n
+	if end <= start {
+		end = start + 1
+	}
+	if end > size {
+		start, end = size-1, size
+	}
+	return start, end
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
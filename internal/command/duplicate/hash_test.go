@@ -0,0 +1,87 @@
+package duplicate
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 왼쪽은 어둡고 오른쪽으로 갈수록 밝아지다가 가운데에 검은 원이 있는 이미지
+func testImage(size int, bg color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := x-size/2, y-size/2
+			if dx*dx+dy*dy < size*size/16 {
+				img.Set(x, y, color.Black)
+				continue
+			}
+			if x < size/4 {
+				img.Set(x, y, bg)
+				continue
+			}
+			v := uint8(x * 255 / size)
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func Test_dhash(t *testing.T) {
+	small := dhash(testImage(32, color.White))
+	// 크기만 다른 같은 이미지
+	assert.LessOrEqual(t, hammingDistance(small, dhash(testImage(128, color.White))), 2)
+	// 투명한 배경은 흰 배경과 같음
+	assert.Equal(t, small, dhash(testImage(32, color.Transparent)))
+
+	// 좌우를 뒤집은 이미지는 다름
+	flipped := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	src := testImage(32, color.White)
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			flipped.Set(31-x, y, src.At(x, y))
+		}
+	}
+	assert.Greater(t, hammingDistance(small, dhash(flipped)), 10)
+
+	// 픽셀 수가 칸보다 적어도 계산됨
+	assert.NotPanics(t, func() { dhash(testImage(3, color.White)) })
+}
+
+func Test_imageHash_gif(t *testing.T) {
+	frame := func(img image.Image) *image.Paletted {
+		p := image.NewPaletted(img.Bounds(), palette.Plan9)
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				p.Set(x, y, img.At(x, y))
+			}
+		}
+		return p
+	}
+	first := frame(testImage(32, color.White))
+	black := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(black, black.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	second := frame(black)
+
+	p := filepath.Join(t.TempDir(), "party.gif")
+	f, err := os.Create(p)
+	require.NoError(t, err)
+	require.NoError(t, gif.EncodeAll(f, &gif.GIF{Image: []*image.Paletted{first, second}, Delay: []int{10, 10}}))
+	require.NoError(t, f.Close())
+
+	// 첫 프레임만 봄
+	got, err := imageHash(p)
+	require.NoError(t, err)
+	assert.Equal(t, dhash(first), got)
+
+	_, err = imageHash(filepath.Join(t.TempDir(), "missing.png"))
+	assert.Error(t, err)
+}
@@ -1,46 +1,38 @@
-package main
+package favorite
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
-	log "github.com/sirupsen/logrus"
-	"github.com/slack-go/slack"
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
 	"os"
-	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
-	"unicode"
-)
 
-const (
-	channelMessageJSONDirPath = "data"
-)
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
 
-var (
-	// 메시지 텍스트내 :+1:, :smile: 이런 이모지를 추출하기 위한 정규표현식
-	emojiPattern = regexp.MustCompile(`:([가-힣a-zA-Z\d-_+]+):`)
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+	"emojicleaner/internal/mrkdwn"
 )
 
-func main() {
-	slackBotToken := os.Getenv("SLACK_BOT_TOKEN")
-
-	client := slack.New(slackBotToken)
-	if _, err := client.AuthTest(); err != nil {
-		log.Fatal(err)
-	}
-
-	// 한 번도 사용하지 않은 이모지를 찾음
-	if err := favorite(client); err != nil {
-		log.Fatal(err)
-	}
-
-	if err := merge(); err != nil {
-		log.Fatal(err)
-	}
+// 사람마다 가장 많이 쓴 이모지 3개를 찾음
+var Command = &cli.Command{
+	Name:  "favorite",
+	Short: "find the top 3 emojis of each person and render favorite_edited.json to html",
+	Setup: func(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			client := slack.New(g.SlackToken())
+			if _, err := client.AuthTestContext(ctx); err != nil {
+				return err
+			}
+			if err := favorite(client, g); err != nil {
+				return err
+			}
+			return merge(g)
+		}
+	},
 }
 
 type row struct {
@@ -49,22 +41,13 @@ type row struct {
 }
 
 // 수동으로 편집한 결과와 emoji:link 맵을 합쳐 html 생성
-func merge() error {
-	bb, err := os.ReadFile("favorite_edited.json")
-	if err != nil {
-		return err
-	}
+func merge(g *cli.Globals) error {
 	var rows []row
-	if err := json.Unmarshal(bb, &rows); err != nil {
-		return err
-	}
-
-	bb, err = os.ReadFile("favorite_map.json")
-	if err != nil {
+	if err := g.Load("favorite_edited.json", &rows); err != nil {
 		return err
 	}
 	var emojiLink map[string]string
-	if err := json.Unmarshal(bb, &emojiLink); err != nil {
+	if err := g.Load("favorite_map.json", &emojiLink); err != nil {
 		return err
 	}
 
@@ -87,17 +70,25 @@ func merge() error {
 		}
 		text += "</div>\n"
 	}
-	return os.WriteFile("output.html", []byte(text), 0644)
+	return os.WriteFile(g.Path("output.html"), []byte(text), 0644)
 }
 
-func favorite(client *slack.Client) error {
-	msgs, err := loadChannelMessages()
+func favorite(client *slack.Client, g *cli.Globals) error {
+	m, err := dataset.LoadManifest(g.Dataset)
+	if err != nil {
+		return err
+	}
+	msgs, err := dataset.LoadMessages(g.Dataset, m)
 	if err != nil {
 		return err
 	}
 
 	// counter: map[유저ID]map[이모지]사용횟수
 	counter := make(map[string]map[string]int)
+	// tones: map[유저ID]map[피부색]사용횟수
+	tones := make(map[string]map[string]int)
+	// 출처별 합계는 로그로만 남김
+	sources := make(map[string]int)
 	for _, msg := range msgs {
 		if msg.User == "" {
 			continue
@@ -106,6 +97,11 @@ func favorite(client *slack.Client) error {
 		if msg.BotID != "" {
 			continue
 		}
+		for source, counts := range emojiusage.BySource(msg) {
+			for _, count := range counts {
+				sources[source] += count
+			}
+		}
 		for user, emojiMap := range countEmojiUsageByUserFromMessage(msg) {
 			// 처음이면 초기화
 			if _, ok := counter[user]; !ok {
@@ -115,8 +111,22 @@ func favorite(client *slack.Client) error {
 				counter[user][emoji] += count
 			}
 		}
+		for user, toneMap := range countSkinTonesByUserFromMessage(msg) {
+			if _, ok := tones[user]; !ok {
+				tones[user] = map[string]int{}
+			}
+			for tone, count := range toneMap {
+				tones[user][tone] += count
+			}
+		}
 	}
 
+	for _, source := range []string{emojiusage.Text, emojiusage.Reaction, emojiusage.Attachment, emojiusage.Block} {
+		log.Infof("%d emojis are used in %s", sources[source], source)
+	}
+
+	logSkinTones(tones)
+
 	counter = rankTop3ByUser(counter)
 	logEmojis(counter)
 
@@ -125,7 +135,7 @@ func favorite(client *slack.Client) error {
 		return err
 	}
 
-	if err := saveJSON("favorite.json", convertUserNameOfCounter(userMap, counter)); err != nil {
+	if err := g.Save("favorite.json", convertUserNameOfCounter(userMap, counter, tones)); err != nil {
 		return err
 	}
 	return nil
@@ -187,48 +197,13 @@ func makeUserMap(client *slack.Client) (map[string]slackUser, error) {
 	return userMap, nil
 }
 
-func normalize(s string) string {
-	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	result, _, err := transform.String(t, s)
-	if err != nil {
-		log.WithError(err).Errorf("cannot normalize '%s'", s)
-		return s
-	}
-
-	return result
-}
-
-// 다해봐야 약 50MB라서 채널을 이용해 produce 하는 대신 한 번에 메모리로 로드함
-func loadChannelMessages() ([]slack.Message, error) {
-	dirs, err := os.ReadDir(channelMessageJSONDirPath)
-	if err != nil {
-		return nil, err
-	}
-
-	allMsgs := make([]slack.Message, 0, 4000)
-	for _, dir := range dirs {
-		bb, err := os.ReadFile(filepath.Join(channelMessageJSONDirPath, dir.Name()))
-		if err != nil {
-			return nil, err
-		}
-
-		msgs := make([]slack.Message, 0, 200)
-		if err := json.Unmarshal(bb, &msgs); err != nil {
-			return nil, err
-		}
-		allMsgs = append(allMsgs, msgs...)
-	}
-
-	log.Infof("%d messages are loaded from %d json files", len(allMsgs), len(dirs))
-	return allMsgs, nil
-}
-
-func countEmojiUsageByUserFromMessage(m slack.Message) map[string]map[string]int {
+// 텍스트, 첨부, 블록의 이모지는 메시지를 보낸 사람이, 리액션은 단 사람이 쓴 것으로 셈
+func countEmojiUsageByUserFromMessage(m dataset.Message) map[string]map[string]int {
 	counter := map[string]map[string]int{
 		m.User: {},
 	}
-	// extract from text
-	for _, name := range extractEmojisFromText(m.Text) {
+	// extract from text, attachments and blocks
+	for _, name := range emojiusage.FromMessage(m) {
 		counter[m.User][name] += 1
 	}
 	// extract from reactions
@@ -238,39 +213,65 @@ func countEmojiUsageByUserFromMessage(m slack.Message) map[string]map[string]int
 			if _, ok := counter[user]; !ok {
 				counter[user] = map[string]int{}
 			}
-			name := removeSkinTone(normalize(r.Name))
+			name := emojiusage.RemoveSkinTone(mrkdwn.NormalizeName(r.Name))
 			counter[user][name] += 1
 		}
 	}
 	return counter
 }
 
-// "+1::skin-tone-3" to "+1"
-func removeSkinTone(s string) string {
-	return strings.Split(s, "::")[0]
+// 텍스트와 첨부는 메시지를 보낸 사람이, 리액션은 단 사람이 고른 피부색으로 셈. 기본 피부색은 세지 않음
+func countSkinTonesByUserFromMessage(m dataset.Message) map[string]map[string]int {
+	counter := map[string]map[string]int{}
+	add := func(user, tone string) {
+		if _, ok := counter[user]; !ok {
+			counter[user] = map[string]int{}
+		}
+		counter[user][tone] += 1
+	}
+	for _, text := range append([]string{m.Text}, emojiusage.AttachmentTexts(m.Attachments)...) {
+		for _, t := range mrkdwn.Tokens(text) {
+			if t.SkinTone != "" {
+				add(m.User, t.SkinTone)
+			}
+		}
+	}
+	for _, r := range m.Reactions {
+		if _, tone := mrkdwn.SplitSkinTone(r.Name); tone != "" {
+			for _, user := range r.Users {
+				add(user, tone)
+			}
+		}
+	}
+	return counter
 }
 
-func extractEmojisFromText(s string) []string {
-	matched := emojiPattern.FindAllStringSubmatch(s, -1)
-	if len(matched) == 0 {
-		return nil
+// 피부색별 합계
+func logSkinTones(tones map[string]map[string]int) {
+	totals := map[string]int{}
+	for _, toneMap := range tones {
+		for tone, count := range toneMap {
+			totals[tone] += count
+		}
+	}
+	names := make([]string, 0, len(totals))
+	for tone := range totals {
+		names = append(names, tone)
 	}
+	sort.Strings(names)
+	for _, tone := range names {
+		log.Infof("%d emojis are used with %s by %d users", totals[tone], tone, countUsersWithTone(tones, tone))
+	}
+}
 
-	emojis := make([]string, 0, len(matched))
-	for _, match := range matched {
-		name := normalize(match[1]) // if matched, length is at least 2
-		// 메시지에서 사용한 :pray::skin-tone-2: 같은 경우 :pary:로만 카운팅하면 충분하기에 스킨톤은 걸러줌
-		if strings.HasPrefix(name, "skin-tone-") {
-			continue
-		}
-		// "2020-02-01 00:00:00" 이런게 파싱돼 :00:이 이모지로 인식되는데 현재 뾰족한 수가 없어 결과 데이터를 보고
-		// 하드코딩해서 이모지로 인식안되게 거름
-		if name == "00" || name == "23" || name == "49" {
-			continue
+func countUsersWithTone(tones map[string]map[string]int, tone string) int {
+	var n int
+	for _, toneMap := range tones {
+		if toneMap[tone] > 0 {
+			n++
 		}
-		emojis = append(emojis, name)
 	}
-	return emojis
+	return n
 }
 
 func rankTop3ByUser(counter map[string]map[string]int) map[string]map[string]int {
@@ -297,7 +298,7 @@ func rankTop3ByUser(counter map[string]map[string]int) map[string]map[string]int
 	return m
 }
 
-func convertUserNameOfCounter(userMap map[string]slackUser, counter map[string]map[string]int) []map[string]interface{} {
+func convertUserNameOfCounter(userMap map[string]slackUser, counter, tones map[string]map[string]int) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0)
 	for slackID, emojiMap := range counter {
 		user, ok := userMap[slackID]
@@ -305,10 +306,15 @@ func convertUserNameOfCounter(userMap map[string]slackUser, counter map[string]m
 		if !ok {
 			continue
 		}
-		result = append(result, map[string]interface{}{
+		row := map[string]interface{}{
 			"user":  user,
 			"emoji": emojiMap,
-		})
+		}
+		// 피부색을 골라 쓴 적이 있는 사람만
+		if len(tones[slackID]) > 0 {
+			row["skin_tones"] = tones[slackID]
+		}
+		result = append(result, row)
 	}
 	return result
 }
@@ -319,14 +325,3 @@ func min(x, y int) int {
 	}
 	return y
 }
-
-func saveJSON(name string, data interface{}) error {
-	bb, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(name, bb, 0644); err != nil {
-		return err
-	}
-	return nil
-}
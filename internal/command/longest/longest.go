@@ -1,41 +1,42 @@
-package main
+package longest
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"unicode"
 	"unicode/utf8"
 
 	log "github.com/sirupsen/logrus"
-	"github.com/slack-go/slack"
-)
 
-const (
-	channelMessageJSONDirPath = "data"
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
 )
 
 var (
 	codePattern    = regexp.MustCompile("(?s)(```.*?```)")
 	urlPattern     = regexp.MustCompile(`(https?://[^|\s]+)`)
 	slackIDPattern = regexp.MustCompile(`<(@|!subteam\^|#)([A-Z\d]+)(>|\|.+>)`)
-	emojiPattern   = regexp.MustCompile(`:([가-힣a-zA-Z\d-_+]+):`)
 )
 
-func main() {
-	// 가장 긴 메시지를 찾음
-	if err := longest(); err != nil {
-		log.Fatal(err)
-	}
+// 가장 긴 메시지를 찾음
+var Command = &cli.Command{
+	Name:  "longest",
+	Short: "find conversation messages longer than 1000 characters",
+	Setup: func(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			return longest(g)
+		}
+	},
 }
 
 type slackMsg struct {
 	Text             string `json:"text"`
 	Length           int    `json:"length"`
-	msg              slack.Message
+	msg              dataset.Message
 	upperLetterCount int
 	numberCount      int
 	alphabetCount    int
@@ -45,15 +46,19 @@ func (m slackMsg) String() string {
 	return fmt.Sprintf("%s (%d)", m.Text, m.Length)
 }
 
-func longest() error {
-	msgs, err := loadChannelMessages()
+func longest(g *cli.Globals) error {
+	m, err := dataset.LoadManifest(g.Dataset)
+	if err != nil {
+		return err
+	}
+	msgs, err := dataset.LoadMessages(g.Dataset, m)
 	if err != nil {
 		return err
 	}
 
 	slackMsgs := make([]slackMsg, 0, len(msgs))
 	for _, m := range msgs {
-		text := normalize(m.Text)
+		text := stripMarkup(m.Text)
 		length := utf8.RuneCountInString(text)
 		// 기본적으로 1천자가 넘어야 긴걸로 인정
 		if length < 1000 {
@@ -73,7 +78,7 @@ func longest() error {
 	})
 
 	log.Infof("total %d >1000 msgs are exist", len(slackMsgs))
-	if err := saveJSON("longest.json", filterNonConversationMessages(slackMsgs)); err != nil {
+	if err := g.Save("longest.json", filterNonConversationMessages(slackMsgs)); err != nil {
 		return err
 	}
 	return nil
@@ -135,7 +140,8 @@ func countAlphabet(s string) int {
 	return count
 }
 
-func normalize(s string) string {
+// 길이를 잴 때 글자로 치지 않을 코드, 링크, 태그, 이모지를 지움
+func stripMarkup(s string) string {
 	// 쓸데없이 긴 코드는 제거
 	s = codePattern.ReplaceAllString(s, "")
 	// URL 링크는 텍스트만 남겨두고 제거
@@ -143,42 +149,6 @@ func normalize(s string) string {
 	// 슬랙 팀이나 유저 태그는 제거
 	s = slackIDPattern.ReplaceAllString(s, "")
 	// 이모지 제거
-	s = emojiPattern.ReplaceAllString(s, "")
+	s = mrkdwn.RemoveEmojis(s)
 	return s
 }
-
-// 다해봐야 약 50MB라서 채널을 이용해 produce 하는 대신 한 번에 메모리로 로드함
-func loadChannelMessages() ([]slack.Message, error) {
-	dirs, err := os.ReadDir(channelMessageJSONDirPath)
-	if err != nil {
-		return nil, err
-	}
-
-	allMsgs := make([]slack.Message, 0, 4000)
-	for _, dir := range dirs {
-		bb, err := os.ReadFile(filepath.Join(channelMessageJSONDirPath, dir.Name()))
-		if err != nil {
-			return nil, err
-		}
-
-		msgs := make([]slack.Message, 0, 200)
-		if err := json.Unmarshal(bb, &msgs); err != nil {
-			return nil, err
-		}
-		allMsgs = append(allMsgs, msgs...)
-	}
-
-	log.Infof("%d messages are loaded from %d json files", len(allMsgs), len(dirs))
-	return allMsgs, nil
-}
-
-func saveJSON(name string, data interface{}) error {
-	bb, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(name, bb, 0644); err != nil {
-		return err
-	}
-	return nil
-}
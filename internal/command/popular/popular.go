@@ -0,0 +1,71 @@
+package popular
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+)
+
+// 가장 반응이 뜨거운 메시지를 찾음
+var Command = &cli.Command{
+	Name:  "popular",
+	Short: "find the 10 messages with the most reactions",
+	Setup: func(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			return popular(g)
+		}
+	},
+}
+
+type slackMsg struct {
+	Msg   dataset.Message `json:"msg"`
+	Count int             `json:"count"`
+}
+
+func (m slackMsg) String() string {
+	return fmt.Sprintf("%s (%d)", m.Msg.Timestamp, m.Count)
+}
+
+func popular(g *cli.Globals) error {
+	m, err := dataset.LoadManifest(g.Dataset)
+	if err != nil {
+		return err
+	}
+	msgs, err := dataset.LoadMessages(g.Dataset, m)
+	if err != nil {
+		return err
+	}
+
+	slackMsgs := make([]slackMsg, len(msgs))
+	for i, m := range msgs {
+		slackMsgs[i] = countReactions(m)
+	}
+
+	sort.Slice(slackMsgs, func(i, j int) bool {
+		return slackMsgs[i].Count > slackMsgs[j].Count
+	})
+
+	// 메시지가 10개보다 적은 데이터셋도 있음
+	if len(slackMsgs) > 10 {
+		slackMsgs = slackMsgs[:10]
+	}
+	if err := g.Save("popular.json", slackMsgs); err != nil {
+		return err
+	}
+	return nil
+}
+
+func countReactions(m dataset.Message) slackMsg {
+	var count int
+	for _, r := range m.Reactions {
+		count += r.Count
+	}
+	return slackMsg{
+		Msg:   m,
+		Count: count,
+	}
+}
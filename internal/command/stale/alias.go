@@ -0,0 +1,120 @@
+package stale
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/archive"
+	"emojicleaner/internal/mrkdwn"
+)
+
+const (
+	aliasUsed      = "used"
+	aliasNeverUsed = "never_used"
+	// 원본이 이모지 보관소에는 있지만 지금은 없는 경우
+	aliasOfDeleted = "alias_of_deleted"
+	// 원본이 기본 이모지인 경우
+	aliasOfStandard = "alias_of_standard"
+	// 원본이 커스텀 이모지도 기본 이모지도 아닌 경우. 보관소에 없는 지워진 이모지의 alias일 수 있음
+	aliasOfUnknown = "alias_of_unknown"
+)
+
+// alias 이름 → 원본 이름
+// GetEmoji는 alias를 {"thumbsup_alias": "alias:thumbsup"}처럼 URL 자리에 넣어서 줌
+type aliasGraph map[string]string
+
+// 슬랙은 alias의 alias를 만들 수 없지만 혹시 몰라 원본이 나올 때까지 따라감
+func (g aliasGraph) resolve(name string) string {
+	seen := make(map[string]bool)
+	for {
+		target, ok := g[name]
+		if !ok || seen[name] {
+			return name
+		}
+		seen[name] = true
+		name = target
+	}
+}
+
+// 원본 이름 → 원본을 가리키는 alias 목록
+func (g aliasGraph) aliasesOf() map[string][]string {
+	m := make(map[string][]string)
+	for alias := range g {
+		target := g.resolve(alias)
+		m[target] = append(m[target], alias)
+	}
+	for _, aliases := range m {
+		sort.Strings(aliases)
+	}
+	return m
+}
+
+type aliasReport struct {
+	Name    string `json:"name"`
+	AliasOf string `json:"alias_of"`
+	Count   int    `json:"count"`
+	Status  string `json:"status"`
+	// 보호 목록의 어떤 규칙으로 보호되는지
+	ProtectedBy string `json:"protected_by,omitempty"`
+}
+
+// 사용량은 원본에 합쳐 세기 때문에 alias 자체가 얼마나 쓰였는지, 원본이 살아있는지는 따로 보고함
+func reportAliases(aliases aliasGraph, emojiMap map[string]emoji, counter map[string]int, deleted map[string]bool, catalog *standardCatalog) []aliasReport {
+	reports := make([]aliasReport, 0, len(aliases))
+	for name := range aliases {
+		target := aliases.resolve(name)
+		r := aliasReport{
+			Name:    name,
+			AliasOf: target,
+			Count:   counter[name],
+		}
+		_, isCustom := emojiMap[target]
+		_, isStandard := catalog.lookup(target)
+		switch {
+		case !isCustom && deleted[target]:
+			r.Status = aliasOfDeleted
+		case !isCustom && isStandard:
+			r.Status = aliasOfStandard
+		case !isCustom:
+			r.Status = aliasOfUnknown
+		case r.Count == 0:
+			r.Status = aliasNeverUsed
+		default:
+			r.Status = aliasUsed
+		}
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Status != reports[j].Status {
+			return reports[i].Status < reports[j].Status
+		}
+		return reports[i].Name < reports[j].Name
+	})
+	return reports
+}
+
+// download -archive로 받아둔 보관소에는 있지만 지금 워크스페이스에 없는 이모지 = 지워진 이모지
+func loadDeletedEmojis(archiveDir string, emojiMap map[string]emoji, aliases aliasGraph) (map[string]bool, error) {
+	deleted := make(map[string]bool)
+	if archiveDir == "" {
+		return deleted, nil
+	}
+
+	index, err := archive.Load(archiveDir)
+	if err != nil {
+		return nil, err
+	}
+	for name := range index.Emojis {
+		name := mrkdwn.NormalizeName(name)
+		if _, ok := emojiMap[name]; ok {
+			continue
+		}
+		if _, ok := aliases[name]; ok {
+			continue
+		}
+		deleted[name] = true
+	}
+	log.Infof("%d deleted emojis are found in archive", len(deleted))
+	return deleted, nil
+}
@@ -0,0 +1,89 @@
+package stale
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_aliasGraph_resolve(t *testing.T) {
+	g := aliasGraph{
+		"thumbsup_alias": "thumbsup",
+		"a":              "b",
+		"b":              "c",
+		"loop1":          "loop2",
+		"loop2":          "loop1",
+	}
+
+	assert.Equal(t, "thumbsup", g.resolve("thumbsup_alias"))
+	assert.Equal(t, "c", g.resolve("a"))
+	assert.Equal(t, "tada", g.resolve("tada"))
+	// 순환해도 멈춰야 함
+	assert.Contains(t, []string{"loop1", "loop2"}, g.resolve("loop1"))
+}
+
+func Test_merge_rollsUpAliases(t *testing.T) {
+	emojiMap := map[string]emoji{
+		"party":  {Name: "party", Link: "https://link/party.png", IsCustom: true},
+		"unused": {Name: "unused", Link: "https://link/unused.png", IsCustom: true},
+	}
+	aliases := aliasGraph{
+		"party2": "party",
+		"party3": "party",
+		"+1":     "thumbsup",
+	}
+	counter := map[string]int{
+		"party":    1,
+		"party2":   2,
+		"+1":       3,
+		"thumbsup": 1,
+	}
+
+	got := merge(emojiMap, aliases, counter)
+
+	assert.Equal(t, []emoji{
+		{Name: "party", Link: "https://link/party.png", IsCustom: true, Count: 3, Aliases: []string{"party2", "party3"}},
+		{Name: "thumbsup", Count: 4, Aliases: []string{"+1"}},
+		{Name: "unused", Link: "https://link/unused.png", IsCustom: true},
+	}, sortByName(got))
+	assert.Equal(t, []emoji{
+		{Name: "unused", Link: "https://link/unused.png", IsCustom: true},
+	}, listUnusedEmojis(got))
+}
+
+func Test_reportAliases(t *testing.T) {
+	emojiMap := map[string]emoji{
+		"party": {Name: "party", IsCustom: true},
+	}
+	aliases := aliasGraph{
+		"party2": "party",
+		"party3": "party",
+		"old2":   "old",
+		"+1":     "thumbsup",
+		"typo2":  "typo",
+	}
+	counter := map[string]int{"party2": 2, "old2": 1}
+	deleted := map[string]bool{"old": true}
+
+	catalog, err := parseStandardCatalog("thumbsup\t+1\t1\n")
+	require.NoError(t, err)
+
+	got := reportAliases(aliases, emojiMap, counter, deleted, catalog)
+
+	assert.Equal(t, []aliasReport{
+		{Name: "old2", AliasOf: "old", Count: 1, Status: aliasOfDeleted},
+		{Name: "+1", AliasOf: "thumbsup", Count: 0, Status: aliasOfStandard},
+		{Name: "typo2", AliasOf: "typo", Count: 0, Status: aliasOfUnknown},
+		{Name: "party3", AliasOf: "party", Count: 0, Status: aliasNeverUsed},
+		{Name: "party2", AliasOf: "party", Count: 2, Status: aliasUsed},
+	}, got)
+}
+
+func sortByName(ee []emoji) []emoji {
+	sort.Slice(ee, func(i, j int) bool {
+		return ee[i].Name < ee[j].Name
+	})
+	return ee
+}
@@ -0,0 +1,191 @@
+package stale
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+	"emojicleaner/internal/mrkdwn"
+)
+
+const (
+	topChannelsLimit = 5
+	monthLayout      = "2006-01"
+)
+
+type channelCount struct {
+	Channel string `json:"channel"`
+	Count   int    `json:"count"`
+}
+
+// 이모지별로 어느 채널에서, 누가, 언제 썼는지 셈
+type breakdown struct {
+	// 이모지 → 채널 이름 → 사용 횟수
+	channels map[string]map[string]int
+	// 이모지 → 유저 ID → 사용 횟수
+	users map[string]map[string]int
+	// 이모지 → "2006-01" → 사용 횟수
+	months map[string]map[string]int
+	// 이모지 → 유저 ID → 마지막으로 쓴 시각
+	lastSeen map[string]map[string]time.Time
+}
+
+func newBreakdown() *breakdown {
+	return &breakdown{
+		channels: make(map[string]map[string]int),
+		users:    make(map[string]map[string]int),
+		months:   make(map[string]map[string]int),
+		lastSeen: make(map[string]map[string]time.Time),
+	}
+}
+
+// 텍스트, 첨부, 블록은 메시지를 보낸 사람이, 리액션은 단 사람이 쓴 것으로 셈
+func (b *breakdown) add(msg dataset.Message) {
+	at, err := dataset.ParseTS(msg.Timestamp)
+	if err != nil {
+		return
+	}
+	month := at.Format(monthLayout)
+	for source, counts := range emojiusage.BySource(msg) {
+		for name, count := range counts {
+			increase(b.channels, name, msg.Channel, count)
+			increase(b.months, name, month, count)
+			if source != emojiusage.Reaction && msg.User != "" {
+				increase(b.users, name, msg.User, count)
+				b.see(name, msg.User, at)
+			}
+		}
+	}
+	for _, r := range msg.Reactions {
+		name := emojiusage.RemoveSkinTone(mrkdwn.NormalizeName(r.Name))
+		for _, user := range r.Users {
+			increase(b.users, name, user, 1)
+			b.see(name, user, at)
+		}
+	}
+}
+
+// 상태, 토픽, 북마크는 채널 메시지가 아니라 채널과 월별 횟수에는 넣지 않음
+func (b *breakdown) addSurface(u surfaceUsage, at time.Time) {
+	if u.User != "" {
+		increase(b.users, u.Name, u.User, 1)
+		b.see(u.Name, u.User, at)
+	}
+}
+
+func (b *breakdown) see(name, user string, at time.Time) {
+	if _, ok := b.lastSeen[name]; !ok {
+		b.lastSeen[name] = make(map[string]time.Time)
+	}
+	if last, ok := b.lastSeen[name][user]; !ok || at.After(last) {
+		b.lastSeen[name][user] = at
+	}
+}
+
+// alias로 쓴 횟수를 원본에 합쳐서 이모지마다 채움. since 이후에 쓴 사람 수는 RecentUsers로 채움
+func (b *breakdown) apply(emojis []emoji, aliases aliasGraph, since time.Time) {
+	channels := resolveCounts(b.channels, aliases)
+	users := resolveCounts(b.users, aliases)
+	months := resolveCounts(b.months, aliases)
+	recent := make(map[string]map[string]bool)
+	for name, seen := range b.lastSeen {
+		name := aliases.resolve(name)
+		for user, at := range seen {
+			if at.Before(since) {
+				continue
+			}
+			if _, ok := recent[name]; !ok {
+				recent[name] = make(map[string]bool)
+			}
+			recent[name][user] = true
+		}
+	}
+	for i, e := range emojis {
+		emojis[i].Channels = len(channels[e.Name])
+		emojis[i].TopChannels = topChannels(channels[e.Name], topChannelsLimit)
+		emojis[i].Users = len(users[e.Name])
+		emojis[i].TopUsers = topUsers(users[e.Name], topUsersLimit)
+		emojis[i].RecentUsers = len(recent[e.Name])
+		if len(months[e.Name]) > 0 {
+			emojis[i].Monthly = months[e.Name]
+		}
+	}
+}
+
+func topChannels(counts map[string]int, limit int) []channelCount {
+	if len(counts) == 0 {
+		return nil
+	}
+	cc := make([]channelCount, 0, len(counts))
+	for ch, count := range counts {
+		cc = append(cc, channelCount{Channel: ch, Count: count})
+	}
+	sort.Slice(cc, func(i, j int) bool {
+		if cc[i].Count != cc[j].Count {
+			return cc[i].Count > cc[j].Count
+		}
+		return cc[i].Channel < cc[j].Channel
+	})
+	if len(cc) > limit {
+		cc = cc[:limit]
+	}
+	return cc
+}
+
+func increase(m map[string]map[string]int, name, key string, count int) {
+	if _, ok := m[name]; !ok {
+		m[name] = make(map[string]int)
+	}
+	m[name][key] += count
+}
+
+func resolveCounts(m map[string]map[string]int, aliases aliasGraph) map[string]map[string]int {
+	resolved := make(map[string]map[string]int, len(m))
+	for name, counts := range m {
+		name := aliases.resolve(name)
+		for key, count := range counts {
+			increase(resolved, name, key, count)
+		}
+	}
+	return resolved
+}
+
+// all_emojis.json의 정렬 기준
+var emojiOrders = map[string]func(a, b emoji) bool{
+	"count":    func(a, b emoji) bool { return a.Count > b.Count },
+	"name":     func(a, b emoji) bool { return a.Name < b.Name },
+	"users":    func(a, b emoji) bool { return a.Users > b.Users },
+	"channels": func(a, b emoji) bool { return a.Channels > b.Channels },
+	// 오래 전에 마지막으로 쓴 이모지부터. 쓰지 않은 이모지가 가장 앞
+	"last_used": func(a, b emoji) bool {
+		return lastUsed(a).Before(lastUsed(b))
+	},
+}
+
+func lastUsed(e emoji) time.Time {
+	if e.LastUsed == nil {
+		return time.Time{}
+	}
+	return *e.LastUsed
+}
+
+// 같은 순위라면 이름순
+func sortEmojis(emojis []emoji, order string) error {
+	less, ok := emojiOrders[order]
+	if !ok {
+		return errors.Errorf("unknown sort order '%s'", order)
+	}
+	sort.SliceStable(emojis, func(i, j int) bool {
+		if less(emojis[i], emojis[j]) {
+			return true
+		}
+		if less(emojis[j], emojis[i]) {
+			return false
+		}
+		return emojis[i].Name < emojis[j].Name
+	})
+	return nil
+}
@@ -0,0 +1,74 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_breakdown_apply(t *testing.T) {
+	b := newBreakdown()
+	b.add(dataset.Message{Message: slack.Message{Msg: slack.Msg{
+		Channel:   "general",
+		User:      "U1",
+		Timestamp: "1672531200.000100", // 2023-01-01
+		Text:      ":party: :party2:",
+		Reactions: []slack.ItemReaction{{Name: "party", Count: 2, Users: []string{"U2", "U3"}}},
+	}}})
+	b.add(dataset.Message{Message: slack.Message{Msg: slack.Msg{
+		Channel:   "random",
+		User:      "U1",
+		Timestamp: "1675209600.000100", // 2023-02-01
+		Text:      ":party:",
+	}}})
+	b.addSurface(surfaceUsage{Source: sourceStatus, Name: "party", User: "U4"}, time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC))
+	emojis := []emoji{{Name: "party", IsCustom: true}, {Name: "unused", IsCustom: true}}
+
+	b.apply(emojis, aliasGraph{"party2": "party"}, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, 2, emojis[0].Channels)
+	assert.Equal(t, []channelCount{{Channel: "general", Count: 4}, {Channel: "random", Count: 1}}, emojis[0].TopChannels)
+	assert.Equal(t, 4, emojis[0].Users)
+	assert.Equal(t, []userCount{{User: "U1", Count: 3}, {User: "U2", Count: 1}, {User: "U3", Count: 1}, {User: "U4", Count: 1}}, emojis[0].TopUsers)
+	// 2월부터는 U1과 상태에 건 U4만 씀
+	assert.Equal(t, 2, emojis[0].RecentUsers)
+	assert.Equal(t, map[string]int{"2023-01": 4, "2023-02": 1}, emojis[0].Monthly)
+	assert.Equal(t, emoji{Name: "unused", IsCustom: true}, emojis[1])
+}
+
+func Test_topChannels(t *testing.T) {
+	got := topChannels(map[string]int{"a": 1, "b": 3, "c": 3, "d": 2}, 3)
+	assert.Equal(t, []channelCount{{Channel: "b", Count: 3}, {Channel: "c", Count: 3}, {Channel: "d", Count: 2}}, got)
+	assert.Nil(t, topChannels(nil, 3))
+}
+
+func Test_sortEmojis(t *testing.T) {
+	old := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := old.AddDate(0, 6, 0)
+	emojis := []emoji{
+		{Name: "c", Count: 5, Users: 1, LastUsed: &recent},
+		{Name: "a", Count: 5, Users: 3, LastUsed: &old},
+		{Name: "b", Count: 9, Users: 2},
+	}
+	names := func() []string {
+		nn := make([]string, 0, len(emojis))
+		for _, e := range emojis {
+			nn = append(nn, e.Name)
+		}
+		return nn
+	}
+
+	require.NoError(t, sortEmojis(emojis, "count"))
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+	require.NoError(t, sortEmojis(emojis, "users"))
+	assert.Equal(t, []string{"a", "b", "c"}, names())
+	// 쓰지 않은 이모지가 가장 앞
+	require.NoError(t, sortEmojis(emojis, "last_used"))
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+	assert.Error(t, sortEmojis(emojis, "size"))
+}
@@ -0,0 +1,160 @@
+package stale
+
+import (
+	"bufio"
+	_ "embed"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate go run gen_catalog.go -gemoji $GEMOJI -codemap $CODEMAP
+
+// 슬랙 기본 이모지 목록. 이름, alias, 피부색을 바꿀 수 있는지가 탭으로 나뉘어 있음
+//
+//go:embed standard_emojis.tsv
+var standardEmojisTSV string
+
+// 이름이 어떤 이모지인지
+const (
+	kindCustom = "custom"
+	// 기본 이모지
+	kindStandard = "standard"
+	// 커스텀 이모지나 기본 이모지의 다른 이름
+	kindAlias = "alias"
+	// 이모지 보관소에만 남아있는 지워진 커스텀 이모지
+	kindDeletedCustom = "deleted_custom"
+	// 오타나 :00: 처럼 이모지가 아닌데 이모지처럼 보이는 경우
+	kindUnknown = "unknown"
+)
+
+type standardCatalog struct {
+	// 이름이나 alias → 대표 이름
+	names map[string]string
+	// 피부색을 바꿀 수 있는 이모지의 대표 이름
+	skinTones map[string]bool
+}
+
+func loadStandardCatalog() (*standardCatalog, error) {
+	return parseStandardCatalog(standardEmojisTSV)
+}
+
+func parseStandardCatalog(tsv string) (*standardCatalog, error) {
+	c := &standardCatalog{
+		names:     make(map[string]string),
+		skinTones: make(map[string]bool),
+	}
+	s := bufio.NewScanner(strings.NewReader(tsv))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, errors.Errorf("invalid standard emoji at line %d: '%s'", n, line)
+		}
+		name := fields[0]
+		c.names[name] = name
+		if fields[1] != "" {
+			for _, alias := range strings.Split(fields[1], ",") {
+				c.names[alias] = name
+			}
+		}
+		c.skinTones[name] = fields[2] == "1"
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// 기본 이모지라면 대표 이름을 반환
+func (c *standardCatalog) lookup(name string) (string, bool) {
+	canonical, ok := c.names[name]
+	return canonical, ok
+}
+
+func (c *standardCatalog) supportsSkinTone(name string) bool {
+	canonical, ok := c.lookup(name)
+	return ok && c.skinTones[canonical]
+}
+
+// 커스텀 alias에 thumbsup → +1 같은 기본 이모지의 alias를 더해 기본 이모지도 대표 이름으로 합쳐 세도록 함
+// 커스텀 이모지나 커스텀 alias와 이름이 겹치면 커스텀을 따름
+func (c *standardCatalog) withAliases(emojiMap map[string]emoji, aliases aliasGraph) aliasGraph {
+	g := make(aliasGraph, len(aliases)+len(c.names))
+	for alias, canonical := range c.names {
+		if alias == canonical {
+			continue
+		}
+		if _, ok := emojiMap[alias]; ok {
+			continue
+		}
+		g[alias] = canonical
+	}
+	for alias, target := range aliases {
+		g[alias] = target
+	}
+	return g
+}
+
+// 메시지에서 찾은 이름이 어떤 이모지인지 구분함
+func (c *standardCatalog) classify(name string, emojiMap map[string]emoji, aliases aliasGraph, deleted map[string]bool) string {
+	if _, ok := emojiMap[name]; ok {
+		return kindCustom
+	}
+	if _, ok := aliases[name]; ok {
+		return kindAlias
+	}
+	if canonical, ok := c.lookup(name); ok {
+		if canonical == name {
+			return kindStandard
+		}
+		return kindAlias
+	}
+	if deleted[name] {
+		return kindDeletedCustom
+	}
+	return kindUnknown
+}
+
+// 합친 뒤의 이모지마다 종류를 채움. alias는 원본에 합쳐졌으니 나오지 않음
+func (c *standardCatalog) apply(emojis []emoji, emojiMap map[string]emoji, aliases aliasGraph, deleted map[string]bool) {
+	for i, e := range emojis {
+		emojis[i].Kind = c.classify(e.Name, emojiMap, aliases, deleted)
+		if emojis[i].Kind == kindStandard {
+			emojis[i].SkinTones = c.skinTones[e.Name]
+		}
+	}
+}
+
+// 메시지에서 찾은 이름을 종류별로 세서 남김
+func (c *standardCatalog) log(counter map[string]int, emojiMap map[string]emoji, aliases aliasGraph, deleted map[string]bool) {
+	kinds := make(map[string]int)
+	for name := range counter {
+		kinds[c.classify(name, emojiMap, aliases, deleted)]++
+	}
+	for _, kind := range []string{kindCustom, kindStandard, kindAlias, kindDeletedCustom, kindUnknown} {
+		log.Infof("%d names are %s emojis", kinds[kind], kind)
+	}
+}
+
+// 오타거나 이모지가 아닌 이름. 많이 나온 것부터
+func listUnknownEmojis(emojis []emoji) []emoji {
+	ee := make([]emoji, 0)
+	for _, e := range emojis {
+		if e.Kind == kindUnknown {
+			ee = append(ee, e)
+		}
+	}
+	sort.SliceStable(ee, func(i, j int) bool {
+		if ee[i].Count != ee[j].Count {
+			return ee[i].Count > ee[j].Count
+		}
+		return ee[i].Name < ee[j].Name
+	})
+	return ee
+}
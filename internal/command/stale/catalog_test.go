@@ -0,0 +1,70 @@
+package stale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadStandardCatalog(t *testing.T) {
+	c, err := loadStandardCatalog()
+	require.NoError(t, err)
+
+	canonical, ok := c.lookup("thumbsup")
+	assert.True(t, ok)
+	assert.Equal(t, "+1", canonical)
+	canonical, ok = c.lookup("flag-us")
+	assert.True(t, ok)
+	assert.Equal(t, "us", canonical)
+	assert.True(t, c.supportsSkinTone("wave"))
+	assert.False(t, c.supportsSkinTone("smile"))
+	// GitHub에만 있는 이모지는 없음
+	_, ok = c.lookup("octocat")
+	assert.False(t, ok)
+
+	_, err = parseStandardCatalog("smile\n")
+	assert.Error(t, err)
+}
+
+func Test_standardCatalog_classify(t *testing.T) {
+	c, err := parseStandardCatalog("# comment\n+1\tthumbsup,thumbs_up\t1\nsmile\t\t0\n")
+	require.NoError(t, err)
+	emojiMap := map[string]emoji{"party": {Name: "party", IsCustom: true}, "thumbs_up": {Name: "thumbs_up", IsCustom: true}}
+	aliases := aliasGraph{"party2": "party"}
+	deleted := map[string]bool{"old": true}
+
+	tests := map[string]string{
+		"party":     kindCustom,
+		"party2":    kindAlias,
+		"smile":     kindStandard,
+		"+1":        kindStandard,
+		"thumbsup":  kindAlias,
+		"thumbs_up": kindCustom,
+		"old":       kindDeletedCustom,
+		"00":        kindUnknown,
+	}
+	for name, kind := range tests {
+		assert.Equal(t, kind, c.classify(name, emojiMap, aliases, deleted), name)
+	}
+
+	// 커스텀 이모지와 이름이 겹치는 기본 alias는 커스텀을 따름
+	assert.Equal(t, aliasGraph{"thumbsup": "+1", "party2": "party"}, c.withAliases(emojiMap, aliases))
+
+	emojis := merge(emojiMap, c.withAliases(emojiMap, aliases), map[string]int{"thumbsup": 2, "+1": 1, "00": 3, "party2": 1})
+	c.apply(emojis, emojiMap, aliases, deleted)
+	assert.Equal(t, []emoji{
+		{Name: "+1", Count: 3, Kind: kindStandard, SkinTones: true, Aliases: []string{"thumbsup"}},
+		{Name: "00", Count: 3, Kind: kindUnknown},
+		{Name: "party", IsCustom: true, Count: 1, Kind: kindCustom, Aliases: []string{"party2"}},
+		{Name: "thumbs_up", IsCustom: true, Kind: kindCustom},
+	}, sortByName(emojis))
+	assert.Equal(t, []emoji{{Name: "00", Count: 3, Kind: kindUnknown}}, listUnknownEmojis(emojis))
+
+	// 슬랙에만 있는 기본 이모지는 slack_emojis.tsv에서 합쳐져 unknown이 아님
+	c, err = loadStandardCatalog()
+	require.NoError(t, err)
+	assert.Equal(t, kindStandard, c.classify("simple_smile", emojiMap, aliases, deleted))
+	assert.Equal(t, kindStandard, c.classify("shipit", emojiMap, aliases, deleted))
+	assert.Equal(t, kindAlias, c.classify("squirrel", emojiMap, aliases, deleted))
+}
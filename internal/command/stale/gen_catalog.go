@@ -0,0 +1,223 @@
+//go:build ignore
+
+// goldmark-emoji의 GitHub 이모지 정의와 kyokomi/emoji의 코드 맵으로 standard_emojis.tsv를 만듦
+// 두 목록에 없는 슬랙에만 있는 기본 이모지(simple_smile, shipit 등)는 손으로 관리하는 slack_emojis.tsv에서 합침
+//
+//	go run gen_catalog.go -gemoji .../goldmark-emoji/definition/github.go -codemap .../kyokomi/emoji/v2/emoji_codemap.go
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	gemojiPattern  = regexp.MustCompile(`Emoji\{Name: "[^"]*", Unicode: \[\]int32\{([\d, ]*)\}, ShortNames: \[\]string\{([^}]*)\}\}`)
+	codemapPattern = regexp.MustCompile(`^\s*":([^":]+):":\s*"([^"]+)",`)
+	tonePattern    = regexp.MustCompile(`_tone[1-5]$`)
+)
+
+const (
+	// 피부색을 바꾸는 문자 U+1F3FB-1F3FF
+	toneFirst = 0x1F3FB
+	toneLast  = 0x1F3FF
+	// 이모지 표현을 강제하는 문자
+	variationSelector = 0xFE0F
+	// 국기를 이루는 문자 U+1F1E6(A)-1F1FF(Z)
+	regionalIndicatorA = 0x1F1E6
+	regionalIndicatorZ = 0x1F1FF
+)
+
+type entry struct {
+	names []string
+	tone  bool
+}
+
+func main() {
+	gemoji := flag.String("gemoji", "", "definition/github.go of github.com/yuin/goldmark-emoji")
+	codemap := flag.String("codemap", "", "emoji_codemap.go of github.com/kyokomi/emoji/v2")
+	slack := flag.String("slack", "slack_emojis.tsv", "hand-maintained tsv of emojis only slack has, appended to the catalog")
+	output := flag.String("output", "standard_emojis.tsv", "file to write the catalog")
+	flag.Parse()
+
+	if err := generate(*gemoji, *codemap, *slack, *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(gemojiPath, codemapPath, slackPath, output string) error {
+	// 피부색을 바꿀 수 있는 이모지의 첫 문자
+	toneBases := make(map[rune]bool)
+	// 이모지 문자 → 코드 맵의 이름
+	codemapNames := make(map[string][]string)
+	f, err := os.Open(codemapPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		m := codemapPattern.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		code, err := strconv.Unquote(`"` + m[2] + `"`)
+		if err != nil {
+			return err
+		}
+		rr := []rune(code)
+		for i, r := range rr {
+			if r >= toneFirst && r <= toneLast && i > 0 {
+				toneBases[rr[0]] = true
+			}
+		}
+		if tonePattern.MatchString(m[1]) {
+			continue
+		}
+		key := stripVariation(rr)
+		codemapNames[key] = append(codemapNames[key], m[1])
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	bb, err := os.ReadFile(gemojiPath)
+	if err != nil {
+		return err
+	}
+	entries := make([]entry, 0)
+	seen := make(map[string]bool)
+	for _, m := range gemojiPattern.FindAllStringSubmatch(string(bb), -1) {
+		var rr []rune
+		for _, s := range strings.Split(m[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			rr = append(rr, rune(n))
+		}
+		// GitHub에만 있는 이모지(octocat 등)는 뺌
+		if len(rr) == 0 || rr[0] == 0xFFFD {
+			continue
+		}
+		names := make([]string, 0)
+		add := func(name string) {
+			// 슬랙 이모지 이름은 모두 소문자
+			if name == "" || seen[name] || strings.ToLower(name) != name {
+				return
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+		for _, s := range strings.Split(m[2], ",") {
+			name, err := strconv.Unquote(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			add(name)
+		}
+		// 슬랙은 국기를 flag-us처럼도 부름
+		if flag := flagName(rr); flag != "" {
+			add(flag)
+		}
+		extra := codemapNames[stripVariation(rr)]
+		sort.Strings(extra)
+		for _, name := range extra {
+			add(name)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		entries = append(entries, entry{names: names, tone: toneBases[rr[0]]})
+	}
+	slackEntries, err := readSlackEmojis(slackPath, seen)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, slackEntries...)
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "# generated by gen_catalog.go. DO NOT EDIT.")
+	fmt.Fprintln(w, "# name\taliases\tskin_tones")
+	for _, e := range entries {
+		tone := "0"
+		if e.tone {
+			tone = "1"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.names[0], strings.Join(e.names[1:], ","), tone)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	log.Infof("%d emojis are written to %s", len(entries), output)
+	return nil
+}
+
+// standard_emojis.tsv와 같은 형식. 이미 있는 이름은 건너뛰어 나중에 두 목록에 추가되더라도 겹치지 않음
+func readSlackEmojis(path string, seen map[string]bool) ([]entry, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry, 0)
+	for n, line := range strings.Split(string(bb), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: invalid emoji '%s'", path, n+1, line)
+		}
+		names := make([]string, 0)
+		for _, name := range append([]string{fields[0]}, strings.Split(fields[1], ",")...) {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		entries = append(entries, entry{names: names, tone: fields[2] == "1"})
+	}
+	return entries, nil
+}
+
+func stripVariation(rr []rune) string {
+	kept := make([]rune, 0, len(rr))
+	for _, r := range rr {
+		if r != variationSelector {
+			kept = append(kept, r)
+		}
+	}
+	return string(kept)
+}
+
+func flagName(rr []rune) string {
+	if len(rr) != 2 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("flag-")
+	for _, r := range rr {
+		if r < regionalIndicatorA || r > regionalIndicatorZ {
+			return ""
+		}
+		b.WriteRune('a' + r - regionalIndicatorA)
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package stale
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+// download -emoji-metadata로 받거나 직접 만든 이모지별 만든 날짜와 만든 사람
+//
+//	{"party": {"created_at": "2025-01-01T00:00:00Z", "uploaded_by": "U12345"}}
+type emojiMetadata struct {
+	CreatedAt  time.Time `json:"created_at"`
+	UploadedBy string    `json:"uploaded_by,omitempty"`
+}
+
+// path를 지정하지 않았다면 데이터셋에 받아둔 파일을 쓰고, 그것도 없다면 빈 맵을 반환
+func loadEmojiMetadata(dir, path string) (map[string]emojiMetadata, error) {
+	metadata := make(map[string]emojiMetadata)
+	if path == "" {
+		path = filepath.Join(dir, dataset.EmojiMetadataJSONName)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			log.Warn("emoji metadata is not found, newly created emojis cannot be told apart")
+			return metadata, nil
+		}
+	}
+
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bb, &metadata); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	normalized := make(map[string]emojiMetadata, len(metadata))
+	for name, md := range metadata {
+		normalized[mrkdwn.NormalizeName(name)] = md
+	}
+	log.Infof("metadata of %d emojis are loaded", len(normalized))
+	return normalized, nil
+}
+
+func applyMetadata(emojis []emoji, metadata map[string]emojiMetadata) {
+	var missing int
+	for i, e := range emojis {
+		if !e.IsCustom {
+			continue
+		}
+		md, ok := metadata[e.Name]
+		if !ok {
+			missing++
+			continue
+		}
+		createdAt := md.CreatedAt
+		emojis[i].CreatedAt = &createdAt
+		emojis[i].UploadedBy = md.UploadedBy
+	}
+	if len(metadata) > 0 && missing > 0 {
+		log.Warnf("metadata of %d emojis are missing", missing)
+	}
+}
+
+// 기준 시각 전 gracePeriod 안에 만들어진 커스텀 이모지는 아직 쓰일 기회가 없었으니 판단에서 뺌
+// 만든 날짜를 모르는 이모지는 판단 대상
+func splitTooNewEmojis(emojis []emoji, now time.Time, gracePeriod time.Duration) (judged, tooNew []emoji) {
+	threshold := now.Add(-gracePeriod)
+	judged = make([]emoji, 0, len(emojis))
+	tooNew = make([]emoji, 0)
+	for _, e := range emojis {
+		if e.IsCustom && e.CreatedAt != nil && e.CreatedAt.After(threshold) {
+			tooNew = append(tooNew, e)
+			continue
+		}
+		judged = append(judged, e)
+	}
+	// 최근에 만든 이모지부터
+	sort.Slice(tooNew, func(i, j int) bool {
+		return tooNew[i].CreatedAt.After(*tooNew[j].CreatedAt)
+	})
+	return judged, tooNew
+}
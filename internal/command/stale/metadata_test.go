@@ -0,0 +1,34 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitTooNewEmojis(t *testing.T) {
+	at := func(days int) *time.Time {
+		v := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		return &v
+	}
+	emojis := []emoji{
+		{Name: "old", IsCustom: true, CreatedAt: at(-100)},
+		{Name: "new", IsCustom: true, CreatedAt: at(-7)},
+		{Name: "newer", IsCustom: true, CreatedAt: at(-1)},
+		{Name: "unknown", IsCustom: true},
+		{Name: "smile"},
+	}
+
+	judged, tooNew := splitTooNewEmojis(emojis, *at(0), 30*24*time.Hour)
+
+	names := func(ee []emoji) []string {
+		nn := make([]string, 0, len(ee))
+		for _, e := range ee {
+			nn = append(nn, e.Name)
+		}
+		return nn
+	}
+	assert.Equal(t, []string{"old", "unknown", "smile"}, names(judged))
+	assert.Equal(t, []string{"newer", "new"}, names(tooNew))
+}
@@ -0,0 +1,29 @@
+package stale
+
+import "emojicleaner/internal/protect"
+
+// 보호하는 커스텀 이모지에 어떤 규칙 때문인지 남김
+func applyProtection(p *protect.Rules, emojis []emoji, aliases aliasGraph) {
+	for i, e := range emojis {
+		if !e.IsCustom {
+			continue
+		}
+		if reason, ok := p.Match(e.Name, aliases.resolve(e.Name)); ok {
+			emojis[i].ProtectedBy = reason
+		}
+	}
+}
+
+// 보호하는 이모지는 판단에서 빼고 따로 보고함
+func splitProtectedEmojis(emojis []emoji) (judged, protected []emoji) {
+	judged = make([]emoji, 0, len(emojis))
+	protected = make([]emoji, 0)
+	for _, e := range emojis {
+		if e.ProtectedBy != "" {
+			protected = append(protected, e)
+			continue
+		}
+		judged = append(judged, e)
+	}
+	return judged, protected
+}
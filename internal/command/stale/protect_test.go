@@ -0,0 +1,31 @@
+package stale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/protect"
+)
+
+func Test_splitProtectedEmojis(t *testing.T) {
+	p := &protect.Rules{Prefixes: []string{"alphabet-"}}
+	require.NoError(t, p.Compile())
+	emojis := []emoji{
+		{Name: "alphabet-white-a", IsCustom: true},
+		{Name: "party", IsCustom: true},
+		{Name: "alphabet-smile"},
+	}
+
+	applyProtection(p, emojis, aliasGraph{})
+	judged, protected := splitProtectedEmojis(emojis)
+
+	assert.Equal(t, []emoji{
+		{Name: "party", IsCustom: true},
+		{Name: "alphabet-smile"},
+	}, judged)
+	assert.Equal(t, []emoji{
+		{Name: "alphabet-white-a", IsCustom: true, ProtectedBy: "prefix:alphabet-"},
+	}, protected)
+}
@@ -0,0 +1,55 @@
+package stale
+
+import "sort"
+
+const topUsersLimit = 5
+
+type userCount struct {
+	User  string `json:"user"`
+	Count int    `json:"count"`
+}
+
+func topUsers(counts map[string]int, limit int) []userCount {
+	if len(counts) == 0 {
+		return nil
+	}
+	uu := make([]userCount, 0, len(counts))
+	for user, count := range counts {
+		uu = append(uu, userCount{User: user, Count: count})
+	}
+	sort.Slice(uu, func(i, j int) bool {
+		if uu[i].Count != uu[j].Count {
+			return uu[i].Count > uu[j].Count
+		}
+		return uu[i].User < uu[j].User
+	})
+	if len(uu) > limit {
+		uu = uu[:limit]
+	}
+	return uu
+}
+
+// 여러 번 쓰였어도 -reach-window 동안 쓴 사람이 minUsers명보다 적은 커스텀 이모지
+// 한 사람만 계속 쓰는 이모지도 정리 대상이 됨. 한 번도 쓰이지 않은 이모지는 unused_emojis.json에 있으니 뺌
+func listLowReachEmojis(emojis []emoji, minUsers int) []emoji {
+	ee := make([]emoji, 0)
+	if minUsers <= 0 {
+		return ee
+	}
+	for _, e := range emojis {
+		if !e.IsCustom || e.LastUsed == nil {
+			continue
+		}
+		if e.RecentUsers < minUsers {
+			ee = append(ee, e)
+		}
+	}
+	// 쓴 사람이 적은 이모지부터
+	sort.SliceStable(ee, func(i, j int) bool {
+		if ee[i].RecentUsers != ee[j].RecentUsers {
+			return ee[i].RecentUsers < ee[j].RecentUsers
+		}
+		return ee[i].Name < ee[j].Name
+	})
+	return ee
+}
@@ -0,0 +1,31 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_listLowReachEmojis(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	emojis := []emoji{
+		{Name: "solo", IsCustom: true, Count: 300, Users: 1, RecentUsers: 1, LastUsed: &at},
+		{Name: "popular", IsCustom: true, Count: 10, Users: 5, RecentUsers: 5, LastUsed: &at},
+		{Name: "faded", IsCustom: true, Count: 20, Users: 8, RecentUsers: 0, LastUsed: &at},
+		{Name: "unused", IsCustom: true},
+		{Name: "smile", Count: 1, Users: 1, RecentUsers: 1, LastUsed: &at},
+	}
+
+	got := listLowReachEmojis(emojis, 3)
+
+	assert.Equal(t, []string{"faded", "solo"}, []string{got[0].Name, got[1].Name})
+	assert.Len(t, got, 2)
+	assert.Empty(t, listLowReachEmojis(emojis, 0))
+}
+
+func Test_topUsers(t *testing.T) {
+	got := topUsers(map[string]int{"U1": 1, "U2": 5, "U3": 5}, 2)
+	assert.Equal(t, []userCount{{User: "U2", Count: 5}, {User: "U3", Count: 5}}, got)
+	assert.Nil(t, topUsers(nil, 2))
+}
@@ -0,0 +1,124 @@
+package stale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+	"emojicleaner/internal/mrkdwn"
+)
+
+// 이모지를 처음 본 시각과 마지막으로 쓴 시각, 쓴 사람
+// 슬랙은 리액션을 단 시각을 알려주지 않아 리액션은 메시지 시각으로 셈
+type usage struct {
+	FirstSeen time.Time
+	LastUsed  time.Time
+	LastUser  string
+}
+
+// 이모지 이름 → 사용 기록
+type usageTracker map[string]usage
+
+func (t usageTracker) add(msg dataset.Message) {
+	at, err := dataset.ParseTS(msg.Timestamp)
+	if err != nil {
+		return
+	}
+	for source, counts := range emojiusage.BySource(msg) {
+		if source == emojiusage.Reaction {
+			continue
+		}
+		for name := range counts {
+			t.record(name, at, msg.User)
+		}
+	}
+	for _, r := range msg.Reactions {
+		// 누가 마지막에 달았는지는 알 수 없어 목록의 마지막 사람으로 함
+		var user string
+		if len(r.Users) > 0 {
+			user = r.Users[len(r.Users)-1]
+		}
+		t.record(emojiusage.RemoveSkinTone(mrkdwn.NormalizeName(r.Name)), at, user)
+	}
+}
+
+func (t usageTracker) record(name string, at time.Time, user string) {
+	u, ok := t[name]
+	if !ok {
+		t[name] = usage{FirstSeen: at, LastUsed: at, LastUser: user}
+		return
+	}
+	if at.Before(u.FirstSeen) {
+		u.FirstSeen = at
+	}
+	if !at.Before(u.LastUsed) {
+		u.LastUsed = at
+		u.LastUser = user
+	}
+	t[name] = u
+}
+
+// alias의 사용 기록을 원본 이모지에 합침
+func (t usageTracker) resolve(aliases aliasGraph) usageTracker {
+	resolved := make(usageTracker, len(t))
+	for name, u := range t {
+		name := aliases.resolve(name)
+		resolved.record(name, u.FirstSeen, u.LastUser)
+		resolved.record(name, u.LastUsed, u.LastUser)
+	}
+	return resolved
+}
+
+func (t usageTracker) apply(emojis []emoji) {
+	for i, e := range emojis {
+		u, ok := t[e.Name]
+		if !ok {
+			continue
+		}
+		firstSeen, lastUsed := u.FirstSeen, u.LastUsed
+		emojis[i].FirstSeen = &firstSeen
+		emojis[i].LastUsed = &lastUsed
+		emojis[i].LastUser = u.LastUser
+	}
+}
+
+// 기간 내에 쓰이긴 했지만 기준 시각 전 staleAfter 동안 쓰이지 않은 커스텀 이모지
+// 한 번도 쓰이지 않은 이모지는 unused_emojis.json에 있으니 여기선 뺌
+func listStaleEmojis(emojis []emoji, now time.Time, staleAfter time.Duration) []emoji {
+	threshold := now.Add(-staleAfter)
+	ee := make([]emoji, 0)
+	for _, e := range emojis {
+		if !e.IsCustom || e.LastUsed == nil {
+			continue
+		}
+		if e.LastUsed.Before(threshold) {
+			ee = append(ee, e)
+		}
+	}
+	// 가장 오래전에 쓰인 이모지부터
+	sort.Slice(ee, func(i, j int) bool {
+		return ee[i].LastUsed.Before(*ee[j].LastUsed)
+	})
+	return ee
+}
+
+// time.ParseDuration에 일 단위가 없어 "180d"처럼 d로 끝나면 일로 처리
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || n < 0 {
+			return 0, errors.Errorf("invalid duration '%s'", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.Errorf("invalid duration '%s'", s)
+	}
+	return d, nil
+}
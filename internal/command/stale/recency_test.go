@@ -0,0 +1,72 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_usageTracker(t *testing.T) {
+	msg := func(ts, user, text string, reactions ...slack.ItemReaction) dataset.Message {
+		return dataset.Message{Message: slack.Message{Msg: slack.Msg{Timestamp: ts, User: user, Text: text, Reactions: reactions}}}
+	}
+	tracker := make(usageTracker)
+	tracker.add(msg("1700000300.000100", "U2", ":party2:"))
+	tracker.add(msg("1700000100.000000", "U1", ":party: :tada:"))
+	tracker.add(msg("1700000200.000000", "U1", "",
+		slack.ItemReaction{Name: "tada::skin-tone-2", Users: []string{"U3", "U4"}, Count: 2}))
+
+	got := tracker.resolve(aliasGraph{"party2": "party"})
+
+	assert.Equal(t, usage{
+		FirstSeen: time.Unix(1700000100, 0),
+		LastUsed:  time.Unix(1700000300, 100000),
+		LastUser:  "U2",
+	}, got["party"])
+	assert.Equal(t, usage{
+		FirstSeen: time.Unix(1700000100, 0),
+		LastUsed:  time.Unix(1700000200, 0),
+		LastUser:  "U4",
+	}, got["tada"])
+}
+
+func Test_listStaleEmojis(t *testing.T) {
+	at := func(days int) *time.Time {
+		v := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+		return &v
+	}
+	emojis := []emoji{
+		{Name: "recent", IsCustom: true, Count: 3, LastUsed: at(300)},
+		{Name: "dead", IsCustom: true, Count: 10, LastUsed: at(10)},
+		{Name: "deader", IsCustom: true, Count: 1, LastUsed: at(5)},
+		{Name: "unused", IsCustom: true},
+		{Name: "smile", Count: 1, LastUsed: at(1)},
+	}
+
+	got := listStaleEmojis(emojis, *at(365), 180*24*time.Hour)
+
+	names := make([]string, 0, len(got))
+	for _, e := range got {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"deader", "dead"}, names)
+}
+
+func Test_parseAge(t *testing.T) {
+	d, err := parseAge("180d")
+	assert.NoError(t, err)
+	assert.Equal(t, 180*24*time.Hour, d)
+
+	d, err = parseAge("36h")
+	assert.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+
+	_, err = parseAge("d")
+	assert.Error(t, err)
+	_, err = parseAge("-1d")
+	assert.Error(t, err)
+}
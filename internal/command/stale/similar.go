@@ -0,0 +1,231 @@
+package stale
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"emojicleaner/internal/unionfind"
+)
+
+const (
+	reasonSameKey      = "same_key"
+	reasonTokens       = "tokens"
+	reasonEditDistance = "edit_distance"
+
+	// 이보다 짧은 이름은 한 글자만 달라도 전혀 다른 이모지라 편집 거리로 비교하지 않음
+	minEditDistanceKeyLength = 4
+	// 토큰이 이만큼은 겹쳐야 비슷한 이름으로 봄
+	minSharedTokens = 2
+	minTokenJaccard = 0.5
+)
+
+// 이름을 비교하기 쉽게 바꿔둔 것
+type nameKey struct {
+	// 구분자를 뺀 소문자 이름. 한글은 자모로 나눔
+	key    []rune
+	tokens map[string]bool
+}
+
+func newNameKey(name string) nameKey {
+	name = strings.ToLower(name)
+	k := nameKey{tokens: make(map[string]bool)}
+	for _, token := range strings.FieldsFunc(name, isNameSeparator) {
+		k.tokens[token] = true
+		k.key = append(k.key, decomposeHangul(token)...)
+	}
+	return k
+}
+
+func isNameSeparator(r rune) bool {
+	return r == '-' || r == '_' || r == '+' || r == '.' || r == '\'' || unicode.IsSpace(r)
+}
+
+// "땡큐"와 "땡쿠"처럼 받침이나 모음 하나만 다른 이름을 가깝게 보도록 완성형 한글을 초성, 중성, 종성으로 나눔
+func decomposeHangul(s string) []rune {
+	const (
+		base      = 0xAC00
+		last      = 0xD7A3
+		choseong  = 0x1100
+		jungseong = 0x1161
+		jongseong = 0x11A7
+	)
+	rr := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r < base || r > last {
+			rr = append(rr, r)
+			continue
+		}
+		i := int(r - base)
+		rr = append(rr, rune(choseong+i/588), rune(jungseong+i%588/28))
+		if t := i % 28; t > 0 {
+			rr = append(rr, rune(jongseong+t))
+		}
+	}
+	return rr
+}
+
+// 두 이름이 비슷한지와 그 이유. maxDistance는 긴 이름의 길이로 나눈 편집 거리의 최댓값
+func similarNames(a, b nameKey, maxDistance float64) (string, bool) {
+	if string(a.key) == string(b.key) {
+		return reasonSameKey, true
+	}
+	var shared int
+	for token := range a.tokens {
+		if b.tokens[token] {
+			shared++
+		}
+	}
+	if shared >= minSharedTokens {
+		union := len(a.tokens) + len(b.tokens) - shared
+		if float64(shared)/float64(union) >= minTokenJaccard {
+			return reasonTokens, true
+		}
+	}
+	if len(a.key) < minEditDistanceKeyLength || len(b.key) < minEditDistanceKeyLength {
+		return "", false
+	}
+	longer := len(a.key)
+	if len(b.key) > longer {
+		longer = len(b.key)
+	}
+	limit := int(maxDistance * float64(longer))
+	diff := len(a.key) - len(b.key)
+	if diff < 0 {
+		diff = -diff
+	}
+	// 길이 차이만으로도 넘는다면 계산하지 않음
+	if diff > limit {
+		return "", false
+	}
+	if levenshtein(a.key, b.key) <= limit {
+		return reasonEditDistance, true
+	}
+	return "", false
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+type similarName struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// 대표 이름과 비슷한 이유. 대표 이름과 직접 비슷하지 않다면 Via와 비슷한 이유
+	Reason      string `json:"reason,omitempty"`
+	Via         string `json:"via,omitempty"`
+	ProtectedBy string `json:"protected_by,omitempty"`
+}
+
+// 비슷한 이름의 이모지 묶음. 가장 많이 쓰인 Canonical을 남기고 Aliases는 Canonical의 alias로 바꾸는 것을 제안함
+type nameCluster struct {
+	Canonical string        `json:"canonical"`
+	Count     int           `json:"count"`
+	Aliases   []string      `json:"aliases"`
+	Emojis    []similarName `json:"emojis"`
+}
+
+// 커스텀 이모지 중 이름이 비슷한 이모지를 묶음. alias는 이미 합쳐져 있으니 원본 이모지만 봄
+// 보호된 이모지는 대표가 될 수는 있지만 alias로 바꾸자고 하지는 않음
+func clusterSimilarNames(emojis []emoji, maxDistance float64) []nameCluster {
+	custom := make([]emoji, 0, len(emojis))
+	for _, e := range emojis {
+		if e.IsCustom {
+			custom = append(custom, e)
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool {
+		return custom[i].Name < custom[j].Name
+	})
+	keys := make([]nameKey, len(custom))
+	for i, e := range custom {
+		keys[i] = newNameKey(e.Name)
+	}
+
+	u := unionfind.New(len(custom))
+	for i := range custom {
+		for j := i + 1; j < len(custom); j++ {
+			if _, ok := similarNames(keys[i], keys[j], maxDistance); ok {
+				u.Union(i, j)
+			}
+		}
+	}
+	clusters := make([]nameCluster, 0)
+	for _, group := range u.Groups() {
+		if len(group) < 2 {
+			continue
+		}
+		// 가장 많이 쓰인 이름. 같다면 짧은 이름, 그 다음은 이름순
+		sort.Slice(group, func(i, j int) bool {
+			a, b := custom[group[i]], custom[group[j]]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			if len(a.Name) != len(b.Name) {
+				return len(a.Name) < len(b.Name)
+			}
+			return a.Name < b.Name
+		})
+		canonical := group[0]
+		c := nameCluster{Canonical: custom[canonical].Name, Aliases: make([]string, 0, len(group)-1)}
+		for n, i := range group {
+			e := custom[i]
+			c.Count += e.Count
+			s := similarName{Name: e.Name, Count: e.Count, ProtectedBy: e.ProtectedBy}
+			if n > 0 {
+				s.Reason, s.Via = explainSimilarity(keys, group, custom, i, canonical, maxDistance)
+				if e.ProtectedBy == "" {
+					c.Aliases = append(c.Aliases, e.Name)
+				}
+			}
+			c.Emojis = append(c.Emojis, s)
+		}
+		clusters = append(clusters, c)
+	}
+	// 합쳤을 때 많이 쓰이는 묶음부터
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+		return clusters[i].Canonical < clusters[j].Canonical
+	})
+	return clusters
+}
+
+// 대표 이름과 바로 비슷하지 않다면 묶음 안에서 비슷한 이름을 찾아 알려줌
+func explainSimilarity(keys []nameKey, group []int, custom []emoji, i, canonical int, maxDistance float64) (string, string) {
+	if reason, ok := similarNames(keys[i], keys[canonical], maxDistance); ok {
+		return reason, ""
+	}
+	for _, j := range group {
+		if j == i {
+			continue
+		}
+		if reason, ok := similarNames(keys[i], keys[j], maxDistance); ok {
+			return reason, custom[j].Name
+		}
+	}
+	return "", ""
+}
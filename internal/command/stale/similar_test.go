@@ -0,0 +1,84 @@
+package stale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_similarNames(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		reason string
+		ok     bool
+	}{
+		{a: "thank-you", b: "thankyou", reason: reasonSameKey, ok: true},
+		{a: "Party_Parrot", b: "party-parrot", reason: reasonSameKey, ok: true},
+		{a: "party-parrot", b: "parrot-party", reason: reasonTokens, ok: true},
+		{a: "party-parrot-fast", b: "party-parrot", reason: reasonTokens, ok: true},
+		{a: "thankyou", b: "thankyu", reason: reasonEditDistance, ok: true},
+		// 자모로 나누면 모음 하나만 다름
+		{a: "땡큐", b: "땡쿠", reason: reasonEditDistance, ok: true},
+		{a: "감사", b: "땡큐"},
+		// 짧은 이름은 편집 거리로 비교하지 않음
+		{a: "ok", b: "on"},
+		{a: "party-time", b: "party-parrot"},
+		{a: "thanks", b: "thankyou"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"~"+tt.b, func(t *testing.T) {
+			reason, ok := similarNames(newNameKey(tt.a), newNameKey(tt.b), 0.2)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func Test_decomposeHangul(t *testing.T) {
+	assert.Equal(t, []rune{0x1104, 0x1162, 0x11BC, 0x110F, 0x1172}, decomposeHangul("땡큐"))
+	assert.Equal(t, []rune("ok"), decomposeHangul("ok"))
+}
+
+func Test_levenshtein(t *testing.T) {
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 4, levenshtein([]rune(""), []rune("abcd")))
+	assert.Equal(t, 0, levenshtein([]rune("같음"), []rune("같음")))
+}
+
+func Test_clusterSimilarNames(t *testing.T) {
+	emojis := []emoji{
+		{Name: "thank-you", IsCustom: true, Count: 3},
+		{Name: "thankyou", IsCustom: true, Count: 10},
+		{Name: "thankyu", IsCustom: true, Count: 1},
+		{Name: "thank_you_", IsCustom: true, ProtectedBy: "name:thank_you_"},
+		{Name: "땡큐", IsCustom: true, Count: 2},
+		{Name: "땡쿠", IsCustom: true, Count: 2},
+		{Name: "party", IsCustom: true, Count: 5},
+		{Name: "thankyou", Count: 100},
+	}
+
+	got := clusterSimilarNames(emojis, 0.2)
+
+	assert.Equal(t, []nameCluster{
+		{
+			Canonical: "thankyou",
+			Count:     14,
+			Aliases:   []string{"thank-you", "thankyu"},
+			Emojis: []similarName{
+				{Name: "thankyou", Count: 10},
+				{Name: "thank-you", Count: 3, Reason: reasonSameKey},
+				{Name: "thankyu", Count: 1, Reason: reasonEditDistance},
+				{Name: "thank_you_", Reason: reasonSameKey, ProtectedBy: "name:thank_you_"},
+			},
+		},
+		{
+			Canonical: "땡쿠",
+			Count:     4,
+			Aliases:   []string{"땡큐"},
+			Emojis: []similarName{
+				{Name: "땡쿠", Count: 2},
+				{Name: "땡큐", Count: 2, Reason: reasonEditDistance},
+			},
+		},
+	}, got)
+}
@@ -0,0 +1,59 @@
+package stale
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+)
+
+// 이모지 이름 → 출처 → 사용 횟수. alias로 쓴 횟수는 원본에 합쳐짐
+type sourceCounter map[string]map[string]int
+
+func (c sourceCounter) add(m dataset.Message) {
+	for source, counts := range emojiusage.BySource(m) {
+		for name, count := range counts {
+			if _, ok := c[name]; !ok {
+				c[name] = make(map[string]int)
+			}
+			c[name][source] += count
+		}
+	}
+}
+
+func (c sourceCounter) addSurface(u surfaceUsage) {
+	if _, ok := c[u.Name]; !ok {
+		c[u.Name] = make(map[string]int)
+	}
+	c[u.Name][u.Source]++
+}
+
+func (c sourceCounter) apply(emojis []emoji, aliases aliasGraph) {
+	resolved := make(sourceCounter)
+	for name, sources := range c {
+		name := aliases.resolve(name)
+		if _, ok := resolved[name]; !ok {
+			resolved[name] = make(map[string]int)
+		}
+		for source, count := range sources {
+			resolved[name][source] += count
+		}
+	}
+	for i, e := range emojis {
+		if sources, ok := resolved[e.Name]; ok {
+			emojis[i].Sources = sources
+		}
+	}
+}
+
+func (c sourceCounter) log() {
+	totals := make(map[string]int)
+	for _, sources := range c {
+		for source, count := range sources {
+			totals[source] += count
+		}
+	}
+	for _, source := range []string{emojiusage.Text, emojiusage.Reaction, emojiusage.Attachment, emojiusage.Block, sourceStatus, sourceTopic, sourcePurpose, sourceBookmark} {
+		log.Infof("%d emojis are used in %s", totals[source], source)
+	}
+}
@@ -0,0 +1,25 @@
+package stale
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+)
+
+func Test_sourceCounter_apply(t *testing.T) {
+	c := make(sourceCounter)
+	c.add(dataset.Message{Message: slack.Message{Msg: slack.Msg{
+		Text:      ":party2:",
+		Reactions: []slack.ItemReaction{{Name: "party", Count: 3}},
+	}}})
+	emojis := []emoji{{Name: "party", IsCustom: true}, {Name: "unused", IsCustom: true}}
+
+	c.apply(emojis, aliasGraph{"party2": "party"})
+
+	assert.Equal(t, map[string]int{emojiusage.Text: 1, emojiusage.Reaction: 3}, emojis[0].Sources)
+	assert.Nil(t, emojis[1].Sources)
+}
@@ -0,0 +1,331 @@
+package stale
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/cli"
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+	"emojicleaner/internal/mrkdwn"
+	"emojicleaner/internal/protect"
+)
+
+// 한 번도 사용하지 않았거나 오랫동안 사용하지 않은 이모지를 찾음
+var Command = &cli.Command{
+	Name:  "stale",
+	Short: "find unused, stale and rarely used emojis",
+	Setup: setup,
+}
+
+func setup(fs *flag.FlagSet, g *cli.Globals) func(ctx context.Context) error {
+	archive := fs.String("archive", "", "emoji archive directory made by download -archive, used to find aliases of deleted emojis")
+	staleAfter := fs.String("stale-after", "180d", "report emojis not used for this long before the end of the dataset window, e.g. 180d, 2160h")
+	emojiMetadata := fs.String("emoji-metadata", "", "json file with creation date and uploader of emojis (default <dataset>/emoji_metadata.json made by download -emoji-metadata)")
+	protect := fs.String("protect", "", "json file listing emojis never to report as unused or stale")
+	order := fs.String("sort", "count", "sort all_emojis.json by count, name, users, channels or last_used")
+	minUsers := fs.Int("min-users", 0, "report emojis used by fewer people than this within -reach-window to low_reach_emojis.json, 0 to disable")
+	reach := fs.String("reach-window", "90d", "window before the end of the dataset window to count distinct users in, e.g. 90d, 2160h")
+	nameDistance := fs.Float64("name-distance", 0.2, "max edit distance divided by name length to group similar emoji names into similar_names.json, 0 for same names without separators only")
+	gracePeriod := fs.String("grace-period", "30d", "do not judge emojis created within this long before the end of the dataset window, e.g. 30d, 720h")
+
+	return func(ctx context.Context) error {
+		after, err := parseAge(*staleAfter)
+		if err != nil {
+			return cli.UsageErrorf("-stale-after: %s", err)
+		}
+		grace, err := parseAge(*gracePeriod)
+		if err != nil {
+			return cli.UsageErrorf("-grace-period: %s", err)
+		}
+		reachWindow, err := parseAge(*reach)
+		if err != nil {
+			return cli.UsageErrorf("-reach-window: %s", err)
+		}
+
+		if *nameDistance < 0 || *nameDistance > 1 {
+			return cli.UsageErrorf("-name-distance must be between 0 and 1, got %g", *nameDistance)
+		}
+		if _, ok := emojiOrders[*order]; !ok {
+			return cli.UsageErrorf("unknown -sort '%s'", *order)
+		}
+
+		return stale(g, *archive, *emojiMetadata, *protect, *order, after, grace, reachWindow, *minUsers, *nameDistance)
+	}
+}
+
+type emoji struct {
+	Name     string `json:"name"`
+	Link     string `json:"link,omitempty"`
+	IsCustom bool   `json:"is_custom"`
+	Count    int    `json:"count"`
+	// custom, standard, deleted_custom, unknown 중 하나
+	Kind string `json:"kind,omitempty"`
+	// 피부색을 바꿀 수 있는 기본 이모지인지
+	SkinTones bool `json:"skin_tones,omitempty"`
+	// 이 이모지를 가리키는 alias. alias로 쓴 횟수도 Count에 합쳐짐
+	Aliases []string `json:"aliases,omitempty"`
+	// 데이터셋 기간 내에서 처음 본 시각과 마지막으로 쓴 시각, 쓴 사람
+	FirstSeen *time.Time `json:"first_seen,omitempty"`
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+	LastUser  string     `json:"last_user,omitempty"`
+	// 커스텀 이모지를 만든 시각과 만든 사람. 메타데이터가 있을 때만 채워짐
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UploadedBy string     `json:"uploaded_by,omitempty"`
+	// 보호 목록의 어떤 규칙으로 보호되는지
+	ProtectedBy string `json:"protected_by,omitempty"`
+	// 출처(text, reaction, attachment, block, status, topic, purpose, bookmark)별 사용 횟수
+	Sources map[string]int `json:"sources,omitempty"`
+	// 쓰인 채널 수와 가장 많이 쓰인 채널
+	Channels    int            `json:"channels,omitempty"`
+	TopChannels []channelCount `json:"top_channels,omitempty"`
+	// 쓴 사람 수와 가장 많이 쓴 사람, -reach-window 동안 쓴 사람 수
+	Users       int         `json:"users,omitempty"`
+	TopUsers    []userCount `json:"top_users,omitempty"`
+	RecentUsers int         `json:"recent_users,omitempty"`
+	// 월("2006-01")별 사용 횟수
+	Monthly map[string]int `json:"monthly,omitempty"`
+	// 피부색("skin-tone-2")별 사용 횟수. Count에도 포함됨
+	Tones map[string]int `json:"tones,omitempty"`
+}
+
+func (e emoji) String() string {
+	return fmt.Sprintf(":%s:(%d)", e.Name, e.Count)
+}
+
+func stale(g *cli.Globals, archive, metadataPath, protectPath, order string, staleAfter, gracePeriod, reachWindow time.Duration, minUsers int, nameDistance float64) error {
+	m, err := dataset.LoadManifest(g.Dataset)
+	if err != nil {
+		return err
+	}
+	// 기간 내 쓰인 이모지는 모두 staleAfter보다 최근에 쓰였으니 결과가 항상 비어있음
+	if m.Window.Latest.Sub(m.Window.Oldest) <= staleAfter {
+		log.Warnf("dataset window is shorter than -stale-after %s, no emoji can be stale", staleAfter)
+	}
+	emojis, aliases, err := loadEmojis(g.Dataset)
+	if err != nil {
+		return err
+	}
+	warnInvalidEmojiNames(emojis)
+	customEmojiMap := makeEmojiMap(emojis)
+	deleted, err := loadDeletedEmojis(archive, customEmojiMap, aliases)
+	if err != nil {
+		return err
+	}
+	metadata, err := loadEmojiMetadata(g.Dataset, metadataPath)
+	if err != nil {
+		return err
+	}
+	protection, err := protect.Load(protectPath)
+	if err != nil {
+		return err
+	}
+	catalog, err := loadStandardCatalog()
+	if err != nil {
+		return err
+	}
+	// thumbsup처럼 기본 이모지의 다른 이름도 대표 이름(+1)에 합쳐 셈
+	resolver := catalog.withAliases(customEmojiMap, aliases)
+
+	msgs, err := dataset.LoadMessages(g.Dataset, m)
+	if err != nil {
+		return err
+	}
+
+	counter := make(emojiusage.Counter)
+	sources := make(sourceCounter)
+	tracker := make(usageTracker)
+	breakdown := newBreakdown()
+	tones := make(toneCounter)
+	for _, msg := range msgs {
+		counter.Add(msg)
+		sources.add(msg)
+		tracker.add(msg)
+		breakdown.add(msg)
+		tones.add(msg)
+	}
+	// 상태, 토픽, 북마크에 걸려있는 이모지는 지금 보이고 있으니 데이터셋 마지막 시각에 쓴 것으로 셈
+	usages, err := loadSurfaceUsages(g.Dataset)
+	if err != nil {
+		return err
+	}
+	for _, u := range usages {
+		counter[u.Name]++
+		sources.addSurface(u)
+		breakdown.addSurface(u, m.Window.Latest)
+		tracker.record(u.Name, m.Window.Latest, u.User)
+	}
+	sources.log()
+	tones.log()
+	catalog.log(counter, customEmojiMap, aliases, deleted)
+
+	emojis = merge(customEmojiMap, resolver, counter)
+	catalog.apply(emojis, customEmojiMap, aliases, deleted)
+	sources.apply(emojis, resolver)
+	breakdown.apply(emojis, resolver, m.Window.Latest.Add(-reachWindow))
+	tones.apply(emojis, resolver)
+	tracker.resolve(resolver).apply(emojis)
+	applyMetadata(emojis, metadata)
+	applyProtection(protection, emojis, aliases)
+	log.Infof("totally %d emojis are used", len(counter))
+	judged, protected := splitProtectedEmojis(emojis)
+	log.Infof("%d emojis are protected", len(protected))
+	judged, tooNew := splitTooNewEmojis(judged, m.Window.Latest, gracePeriod)
+	log.Infof("%d emojis are too new to judge", len(tooNew))
+	unused := listUnusedEmojis(judged)
+	log.Infof("%d emojis are unused", len(unused))
+	staleEmojis := listStaleEmojis(judged, m.Window.Latest, staleAfter)
+	log.Infof("%d emojis are not used for %s", len(staleEmojis), staleAfter)
+	lowReach := listLowReachEmojis(judged, minUsers)
+	if minUsers > 0 {
+		log.Infof("%d emojis are used by fewer than %d people for %s", len(lowReach), minUsers, reachWindow)
+	}
+	similar := clusterSimilarNames(emojis, nameDistance)
+	log.Infof("%d clusters of similar names are found", len(similar))
+	unknown := listUnknownEmojis(emojis)
+	log.Infof("%d names are neither custom nor standard emojis", len(unknown))
+	aliasReports := reportAliases(aliases, customEmojiMap, counter, deleted, catalog)
+	for i, r := range aliasReports {
+		aliasReports[i].ProtectedBy, _ = protection.Match(r.Name, r.AliasOf)
+	}
+	log.Infof("%d aliases are found", len(aliasReports))
+
+	if err := sortEmojis(emojis, order); err != nil {
+		return err
+	}
+	if err := g.Save("all_emojis.json", emojis); err != nil {
+		return err
+	}
+	if err := g.Save("unused_emojis.json", unused); err != nil {
+		return err
+	}
+	if err := g.Save("stale_emojis.json", staleEmojis); err != nil {
+		return err
+	}
+	if minUsers > 0 {
+		if err := g.Save("low_reach_emojis.json", lowReach); err != nil {
+			return err
+		}
+	}
+	if err := g.Save("too_new_emojis.json", tooNew); err != nil {
+		return err
+	}
+	if err := g.Save("protected_emojis.json", protected); err != nil {
+		return err
+	}
+	if err := g.Save("aliases.json", aliasReports); err != nil {
+		return err
+	}
+	if err := g.Save("similar_names.json", similar); err != nil {
+		return err
+	}
+	if err := g.Save("unknown_emojis.json", unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+// alias는 별개의 이모지가 아니기에 원본 이모지와 나눠서 반환
+func loadEmojis(dir string) ([]emoji, aliasGraph, error) {
+	v, err := dataset.LoadEmojis(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emojis := make([]emoji, 0, len(v.Links))
+	for name, link := range v.Links {
+		emojis = append(emojis, emoji{
+			Name:     name,
+			Link:     link,
+			IsCustom: true,
+		})
+	}
+	sort.Slice(emojis, func(i, j int) bool {
+		return emojis[i].Name < emojis[j].Name
+	})
+	aliases := aliasGraph(v.Aliases)
+	log.Infof("%d emojis and %d aliases are loaded from json file", len(emojis), len(aliases))
+	return emojis, aliases, nil
+}
+
+// 슬랙이 받아주지 않는 이름은 메시지에서 찾을 수 없어 항상 사용하지 않은 것으로 나오니 경고만 하고 그대로 판단함
+func warnInvalidEmojiNames(ee []emoji) {
+	for _, e := range ee {
+		if !mrkdwn.ValidName(e.Name) {
+			log.Warnf("'%s' is not a valid emoji name, it cannot be found in messages", e.Name)
+		}
+	}
+}
+
+func makeEmojiMap(ee []emoji) map[string]emoji {
+	m := make(map[string]emoji)
+	for _, e := range ee {
+		m[e.Name] = e
+	}
+	return m
+}
+
+// alias로 쓴 횟수는 원본 이모지에 합쳐서 셈
+func merge(emojiMap map[string]emoji, aliases aliasGraph, counter map[string]int) []emoji {
+	aliasesOf := aliases.aliasesOf()
+	m := make(map[string]emoji)
+	for name, count := range counter {
+		name := aliases.resolve(name)
+		if e, ok := m[name]; ok {
+			e.Count += count
+			m[name] = e
+			continue
+		}
+		// 커스텀 이모지인 경우 emojiMap에 존재
+		if e, ok := emojiMap[name]; ok {
+			m[name] = emoji{
+				Name:     e.Name,
+				Link:     e.Link,
+				IsCustom: e.IsCustom,
+				Count:    e.Count + count,
+				Aliases:  aliasesOf[name],
+			}
+		} else { // +1, smile 처럼 기본 제공 이모지인 경우
+			m[name] = emoji{
+				Name:     name,
+				Link:     "",
+				IsCustom: false,
+				Count:    count,
+				Aliases:  aliasesOf[name],
+			}
+		}
+	}
+	emojis := make([]emoji, 0, len(m))
+	for _, e := range m {
+		emojis = append(emojis, e)
+	}
+	// 커스텀 이모지인데 1번도 사용되지 않은 경우
+	for name, e := range emojiMap {
+		if _, ok := m[name]; !ok {
+			e.Aliases = aliasesOf[name]
+			emojis = append(emojis, e)
+		}
+	}
+	sort.Slice(emojis, func(i, j int) bool {
+		return emojis[i].Count > emojis[j].Count
+	})
+	return emojis
+}
+
+func listUnusedEmojis(emojis []emoji) []emoji {
+	ee := make([]emoji, 0, len(emojis))
+	for _, e := range emojis {
+		if e.Count == 0 {
+			ee = append(ee, e)
+		}
+	}
+	sort.Slice(ee, func(i, j int) bool {
+		return ee[i].Name < ee[j].Name
+	})
+	return ee
+}
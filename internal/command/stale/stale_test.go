@@ -0,0 +1,37 @@
+package stale
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+)
+
+func Test_loadEmojis(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, dataset.EmojisJSONName), []byte(`{
+		"Party": "https://emoji/party.png",
+		"がんばって": "https://emoji/ganbatte.png",
+		"don't": "https://emoji/dont.png",
+		"a b": "https://emoji/invalid.png",
+		"PARTY2": "alias:Party"
+	}`), 0644))
+
+	emojis, aliases, err := loadEmojis(dir)
+	require.NoError(t, err)
+	// 올바르지 않은 이름도 멈추지 않고 그대로 불러옴
+	warnInvalidEmojiNames(emojis)
+
+	names := make([]string, 0, len(emojis))
+	for _, e := range emojis {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"a b", "don't", "party", "がんばって"}, names)
+	assert.Equal(t, aliasGraph{"party2": "party"}, aliases)
+	assert.Equal(t, []string{"party", "がんばって", "don't"}, emojiusage.FromText(":PARTY: :がんばって: :don't: :a b:"))
+}
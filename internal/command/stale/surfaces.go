@@ -0,0 +1,103 @@
+package stale
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+)
+
+// 메시지 밖에서 이모지가 쓰이는 곳
+const (
+	sourceStatus   = "status"
+	sourceTopic    = "topic"
+	sourcePurpose  = "purpose"
+	sourceBookmark = "bookmark"
+)
+
+// 상태, 채널 토픽과 목적, 북마크에 지금 걸려있는 이모지
+type surfaceUsage struct {
+	Source string
+	Name   string
+	User   string
+}
+
+// download가 받아둔 users.json, bookmarks.json, topics.json에서 이모지를 찾음. 없는 파일은 건너뜀
+func loadSurfaceUsages(dir string) ([]surfaceUsage, error) {
+	usages := make([]surfaceUsage, 0)
+
+	var users []struct {
+		ID          string `json:"id"`
+		StatusEmoji string `json:"status_emoji"`
+		StatusText  string `json:"status_text"`
+	}
+	if ok, err := readOptionalJSON(filepath.Join(dir, dataset.UsersJSONName), &users); err != nil {
+		return nil, err
+	} else if !ok {
+		log.Warnf("'%s' is not found, status emojis are not counted", dataset.UsersJSONName)
+	}
+	for _, u := range users {
+		for _, name := range emojiusage.FromText(u.StatusEmoji + " " + u.StatusText) {
+			usages = append(usages, surfaceUsage{Source: sourceStatus, Name: name, User: u.ID})
+		}
+	}
+
+	var bookmarks []struct {
+		Title string `json:"title"`
+		Emoji string `json:"emoji"`
+	}
+	if ok, err := readOptionalJSON(filepath.Join(dir, dataset.BookmarksJSONName), &bookmarks); err != nil {
+		return nil, err
+	} else if !ok {
+		log.Warnf("'%s' is not found, bookmark emojis are not counted", dataset.BookmarksJSONName)
+	}
+	for _, b := range bookmarks {
+		for _, name := range emojiusage.FromText(b.Emoji + " " + b.Title) {
+			usages = append(usages, surfaceUsage{Source: sourceBookmark, Name: name})
+		}
+	}
+
+	var topics []struct {
+		Topic          string `json:"topic"`
+		TopicCreator   string `json:"topic_creator"`
+		Purpose        string `json:"purpose"`
+		PurposeCreator string `json:"purpose_creator"`
+	}
+	// download가 받을 채널의 토픽과 목적만 매번 새로 저장함. 예전 데이터셋에는 없으니 다시 받아야 셈
+	if ok, err := readOptionalJSON(filepath.Join(dir, dataset.TopicsJSONName), &topics); err != nil {
+		return nil, err
+	} else if !ok {
+		log.Warnf("'%s' is not found, channel topic and purpose emojis are not counted", dataset.TopicsJSONName)
+	}
+	for _, ch := range topics {
+		for _, name := range emojiusage.FromText(ch.Topic) {
+			usages = append(usages, surfaceUsage{Source: sourceTopic, Name: name, User: ch.TopicCreator})
+		}
+		for _, name := range emojiusage.FromText(ch.Purpose) {
+			usages = append(usages, surfaceUsage{Source: sourcePurpose, Name: name, User: ch.PurposeCreator})
+		}
+	}
+
+	log.Infof("%d emojis are found outside messages", len(usages))
+	return usages, nil
+}
+
+// 파일이 없으면 false를 반환
+func readOptionalJSON(path string, v interface{}) (bool, error) {
+	bb, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(bb, v); err != nil {
+		return false, errors.Wrap(err, path)
+	}
+	return true, nil
+}
@@ -0,0 +1,42 @@
+package stale
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_loadSurfaceUsages(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	// 아무 파일도 없으면 비어있음
+	got, err := loadSurfaceUsages(dir)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	write(dataset.UsersJSONName, `[{"id":"U1","status_emoji":":palm_tree:","status_text":"휴가 :airplane:"}]`)
+	write(dataset.BookmarksJSONName, `[{"channel_id":"C1","title":"위키","emoji":":book:"}]`)
+	write(dataset.TopicsJSONName, `[
+		{"channel_id":"C1","topic":"배포 :rocket:","topic_creator":"U2","purpose":":wave: 인사","purpose_creator":"U3"}
+	]`)
+	// 채널 목록 캐시의 토픽은 오래됐을 수 있어 세지 않음
+	write(dataset.ChannelsJSONName, `{"types":["public_channel"],"channels":[{"id":"C1","topic":{"value":":ghost:"}}]}`)
+
+	got, err = loadSurfaceUsages(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []surfaceUsage{
+		{Source: sourceStatus, Name: "palm_tree", User: "U1"},
+		{Source: sourceStatus, Name: "airplane", User: "U1"},
+		{Source: sourceBookmark, Name: "book"},
+		{Source: sourceTopic, Name: "rocket", User: "U2"},
+		{Source: sourcePurpose, Name: "wave", User: "U3"},
+	}, got)
+}
@@ -0,0 +1,58 @@
+package stale
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/emojiusage"
+	"emojicleaner/internal/mrkdwn"
+)
+
+// 이모지 이름 → 피부색("skin-tone-2") → 사용 횟수. 피부색을 고르지 않고 쓴 기본 피부색은 세지 않음
+// 블록은 사람이 보낸 메시지라면 텍스트와 같은 내용이라 텍스트, 첨부, 리액션에서만 셈
+type toneCounter map[string]map[string]int
+
+func (c toneCounter) add(m dataset.Message) {
+	texts := append([]string{m.Text}, emojiusage.AttachmentTexts(m.Attachments)...)
+	for _, text := range texts {
+		for _, t := range mrkdwn.Tokens(text) {
+			if t.SkinTone != "" {
+				increase(c, mrkdwn.NormalizeName(t.Name), t.SkinTone, 1)
+			}
+		}
+	}
+	for _, r := range m.Reactions {
+		if name, tone := mrkdwn.SplitSkinTone(mrkdwn.NormalizeName(r.Name)); tone != "" {
+			increase(c, name, tone, r.Count)
+		}
+	}
+}
+
+// alias로 쓴 횟수는 원본에 합쳐서 채움
+func (c toneCounter) apply(emojis []emoji, aliases aliasGraph) {
+	resolved := resolveCounts(c, aliases)
+	for i, e := range emojis {
+		if tones, ok := resolved[e.Name]; ok {
+			emojis[i].Tones = tones
+		}
+	}
+}
+
+func (c toneCounter) log() {
+	totals := make(map[string]int)
+	for _, tones := range c {
+		for tone, count := range tones {
+			totals[tone] += count
+		}
+	}
+	tones := make([]string, 0, len(totals))
+	for tone := range totals {
+		tones = append(tones, tone)
+	}
+	sort.Strings(tones)
+	for _, tone := range tones {
+		log.Infof("%d emojis are used with %s", totals[tone], tone)
+	}
+}
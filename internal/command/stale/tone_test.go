@@ -0,0 +1,29 @@
+package stale
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"emojicleaner/internal/dataset"
+)
+
+func Test_toneCounter(t *testing.T) {
+	c := make(toneCounter)
+	c.add(dataset.Message{Message: slack.Message{Msg: slack.Msg{
+		Text: ":wave::skin-tone-2: :wave: :hi::skin-tone-2:",
+		Reactions: []slack.ItemReaction{
+			{Name: "+1::skin-tone-3", Count: 5},
+			{Name: "+1", Count: 10},
+		},
+		Attachments: []slack.Attachment{{Text: ":wave::skin-tone-5:"}},
+	}}})
+	emojis := []emoji{{Name: "wave"}, {Name: "+1"}, {Name: "smile"}}
+
+	c.apply(emojis, aliasGraph{"hi": "wave"})
+
+	assert.Equal(t, map[string]int{"skin-tone-2": 2, "skin-tone-5": 1}, emojis[0].Tones)
+	assert.Equal(t, map[string]int{"skin-tone-3": 5}, emojis[1].Tones)
+	assert.Nil(t, emojis[2].Tones)
+}
@@ -0,0 +1,97 @@
+// Package dataset은 download가 만드는 데이터셋의 형식을 정하고 분석 커맨드에서 읽고 결과를 저장함
+package dataset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+// download가 데이터셋에 저장하는 파일
+const (
+	ManifestJSONName      = "manifest.json"
+	EmojisJSONName        = "emojis.json"
+	EmojiMetadataJSONName = "emoji_metadata.json"
+	ChannelsJSONName      = "channels.json"
+	UsersJSONName         = "users.json"
+	BookmarksJSONName     = "bookmarks.json"
+	TopicsJSONName        = "topics.json"
+	MessagesDirName       = "messages"
+
+	// emojis.json에서 alias는 URL 자리에 "alias:원본"이 들어있음
+	AliasPrefix = "alias:"
+)
+
+// download가 블록을 지우면서 블록 안의 이모지만 뽑아둔 메시지
+type Message struct {
+	slack.Message
+	// 본문과 첨부의 블록에 들어있던 이모지. 사람이 보낸 메시지는 rich_text 블록이 Text와 같은 내용이라 분석할 때 Text와 겹치는 만큼은 빼고 셈
+	BlockEmojis []string `json:"block_emojis,omitempty"`
+}
+
+// 분석 커맨드가 데이터셋을 열 때 부름. 다 받지 못한 데이터셋이라면 경고만 남김
+func LoadManifest(dir string) (*Manifest, error) {
+	m, err := ReadManifest(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Errorf("'%s' is not a dataset, run download first", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !m.Complete {
+		log.Warnf("'%s' is not downloaded completely, some channels may be missing", dir)
+	}
+	log.Infof("dataset of %s(%s) from %s to %s", m.Workspace, m.WorkspaceID, m.Window.Oldest.Format(time.RFC3339), m.Window.Latest.Format(time.RFC3339))
+	return m, nil
+}
+
+// 다해봐야 약 50MB라서 채널을 이용해 produce 하는 대신 한 번에 메모리로 로드함
+// 데이터셋 기간을 모두 담고 있지 않은 채널은 결과를 왜곡하기에 건너뜀
+func LoadMessages(dir string, m *Manifest) ([]Message, error) {
+	oldest := m.Window.OldestTS()
+	latest := m.Window.LatestTS()
+
+	allMsgs := make([]Message, 0, 4000)
+	var loaded int
+	for _, ch := range m.Channels {
+		if ch.Oldest != oldest || ch.Latest != latest {
+			log.WithField("channel", "#"+ch.Name).Warn("skipped channel not synced for the dataset window")
+			continue
+		}
+		bb, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ch.File)))
+		if err != nil {
+			return nil, err
+		}
+
+		msgs := make([]Message, 0, 200)
+		if err := json.Unmarshal(bb, &msgs); err != nil {
+			return nil, errors.Wrap(err, ch.File)
+		}
+		// 저장된 메시지에는 채널이 없어 채널별로 셀 수 있도록 채널 이름을 넣어줌
+		for i := range msgs {
+			msgs[i].Channel = ch.Name
+		}
+		allMsgs = append(allMsgs, msgs...)
+		loaded++
+	}
+
+	log.Infof("%d messages are loaded from %d json files", len(allMsgs), loaded)
+	return allMsgs, nil
+}
+
+// 사람이 읽기 좋게 들여쓴 JSON으로 저장
+func SaveJSON(name string, data interface{}) error {
+	bb, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, bb, 0644); err != nil {
+		return err
+	}
+	return nil
+}
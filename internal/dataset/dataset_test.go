@@ -0,0 +1,93 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadManifest(dir)
+	assert.EqualError(t, err, "'"+dir+"' is not a dataset, run download first")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestJSONName), []byte(`{"version":2}`), 0644))
+	_, err = LoadManifest(dir)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestJSONName), []byte(`{
+		"version": 1,
+		"workspace": "team",
+		"window": {"oldest": "2022-01-01T00:00:00Z", "latest": "2022-07-01T00:00:00Z"},
+		"complete": true,
+		"channels": [{"id": "C1", "name": "general", "file": "messages/C1.json", "oldest": "1640995200", "latest": "1656633600"}]
+	}`), 0644))
+	m, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "team", m.Workspace)
+	assert.Equal(t, time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), m.Window.Latest)
+	assert.Len(t, m.Channels, 1)
+}
+
+func TestLoadMessages(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "messages"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messages", "C1.json"), []byte(`[
+		{"ts": "1650000000.000100", "user": "U1", "text": ":tada:", "block_emojis": ["tada"]}
+	]`), 0644))
+	m := &Manifest{
+		Window: Window{
+			Oldest: time.Unix(1640995200, 0),
+			Latest: time.Unix(1656633600, 0),
+		},
+		Channels: []Channel{
+			{ID: "C1", Name: "general", File: "messages/C1.json", Oldest: "1640995200", Latest: "1656633600"},
+			// 기간이 다른 채널은 파일이 없어도 건너뜀
+			{ID: "C2", Name: "random", File: "messages/C2.json", Oldest: "1640995200", Latest: "1650000000"},
+		},
+	}
+
+	msgs, err := LoadMessages(dir, m)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "general", msgs[0].Channel)
+	assert.Equal(t, "U1", msgs[0].User)
+	assert.Equal(t, []string{"tada"}, msgs[0].BlockEmojis)
+}
+
+func TestSaveJSON(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "out.json")
+
+	require.NoError(t, SaveJSON(p, map[string]int{"tada": 1}))
+
+	bb, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"tada\": 1\n}", string(bb))
+}
+
+func TestManifest_SetChannel(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{Version: ManifestVersion}
+
+	m.SetChannel(Channel{ID: "C2", Name: "random", Oldest: "1640995200"})
+	m.SetChannel(Channel{ID: "C1", Name: "general", Oldest: "1640995200"})
+	// 같은 채널은 덮어씀
+	m.SetChannel(Channel{ID: "C2", Name: "random", Oldest: "1650000000"})
+	require.NoError(t, m.Save(dir))
+
+	got, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, got.Channels, 2)
+	// 이름 순으로 저장됨
+	assert.Equal(t, "general", got.Channels[0].Name)
+	ch, ok := got.Channel("C2")
+	assert.True(t, ok)
+	assert.Equal(t, "1650000000", ch.Oldest)
+	_, ok = got.Channel("C3")
+	assert.False(t, ok)
+}
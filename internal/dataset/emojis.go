@@ -0,0 +1,55 @@
+package dataset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"emojicleaner/internal/mrkdwn"
+)
+
+// emojis.json을 원본 이모지와 alias로 나눈 것. 이름은 모두 정규화되어 있음
+type Emojis struct {
+	// 원본 이름 -> 이미지 URL
+	Links map[string]string
+	// alias 이름 -> 원본 이름
+	Aliases map[string]string
+}
+
+// download가 저장한 emojis.json을 읽음. 없으면 os.ErrNotExist를 그대로 반환함
+func LoadEmojis(dir string) (*Emojis, error) {
+	path := filepath.Join(dir, EmojisJSONName)
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v := make(map[string]string)
+	if err := json.Unmarshal(bb, &v); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	e := &Emojis{
+		Links:   make(map[string]string, len(v)),
+		Aliases: make(map[string]string),
+	}
+	for name, value := range v {
+		name := mrkdwn.NormalizeName(name)
+		if target, ok := AliasTarget(value); ok {
+			e.Aliases[name] = mrkdwn.NormalizeName(target)
+			continue
+		}
+		e.Links[name] = value
+	}
+	return e, nil
+}
+
+// emoji.list의 값이 "alias:원본"이면 원본 이름을 반환함. 원본 이름은 슬랙이 준 그대로임
+func AliasTarget(value string) (string, bool) {
+	if !strings.HasPrefix(value, AliasPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, AliasPrefix), true
+}
@@ -0,0 +1,25 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEmojis(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadEmojis(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, EmojisJSONName), []byte(`{
+		"Party": "https://emoji/party.png",
+		"PARTY2": "alias:Party"
+	}`), 0644))
+	emojis, err := LoadEmojis(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"party": "https://emoji/party.png"}, emojis.Links)
+	assert.Equal(t, map[string]string{"party2": "party"}, emojis.Aliases)
+}
@@ -0,0 +1,120 @@
+package dataset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// manifest 형식이 바뀌면 올려서 분석 커맨드가 읽지 못하는 데이터셋을 알아챌 수 있게 함
+const ManifestVersion = 1
+
+// 데이터셋 디렉토리에 어느 워크스페이스의 어느 기간, 어느 채널이 담겨있는지 기록
+//
+//	<dataset>/manifest.json
+//	<dataset>/emojis.json
+//	<dataset>/channels.json
+//	<dataset>/messages/<채널>.json
+type Manifest struct {
+	Version      int       `json:"version"`
+	ToolVersion  string    `json:"tool_version"`
+	WorkspaceID  string    `json:"workspace_id"`
+	Workspace    string    `json:"workspace"`
+	Window       Window    `json:"window"`
+	Sources      *Sources  `json:"sources"`
+	Complete     bool      `json:"complete"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	Channels     []Channel `json:"channels"`
+}
+
+// 메시지를 불러온 기간. [Oldest, Latest]
+type Window struct {
+	Oldest time.Time `json:"oldest"`
+	Latest time.Time `json:"latest"`
+}
+
+// 채널의 Oldest, Latest와 비교할 수 있는 슬랙 타임스탬프
+func (w Window) OldestTS() string {
+	return strconv.FormatInt(w.Oldest.Unix(), 10)
+}
+
+func (w Window) LatestTS() string {
+	return strconv.FormatInt(w.Latest.Unix(), 10)
+}
+
+// 어떤 대화를 받았는지 보고하기 위해 종류별로 받은 채널 수를 셈
+type Sources struct {
+	Types      []string       `json:"types"`
+	Downloaded map[string]int `json:"downloaded"`
+	Skipped    map[string]int `json:"skipped"`
+}
+
+func NewSources(types []string) *Sources {
+	return &Sources{
+		Types:      types,
+		Downloaded: make(map[string]int),
+		Skipped:    make(map[string]int),
+	}
+}
+
+// 채널별로 저장된 메시지가 빠짐없이 담고 있는 기간을 기록해두고 다음 실행 땐 그 이후 메시지만 불러옴
+// Oldest, Latest가 manifest의 Window와 같아야 이번 기간을 모두 담고 있는 채널임
+type Channel struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	File     string    `json:"file"`
+	Messages int       `json:"messages"`
+	Oldest   string    `json:"oldest"`
+	Latest   string    `json:"latest"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// manifest가 없으면 os.ErrNotExist를 그대로 반환함
+func ReadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestJSONName)
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(bb, &m); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	if m.Version != ManifestVersion {
+		return nil, errors.Errorf("%s: unsupported manifest version %d", path, m.Version)
+	}
+	return &m, nil
+}
+
+func (m *Manifest) Save(dir string) error {
+	sort.Slice(m.Channels, func(i, j int) bool {
+		return m.Channels[i].Name < m.Channels[j].Name
+	})
+	return SaveJSON(filepath.Join(dir, ManifestJSONName), m)
+}
+
+func (m *Manifest) Channel(id string) (Channel, bool) {
+	for _, ch := range m.Channels {
+		if ch.ID == id {
+			return ch, true
+		}
+	}
+	return Channel{}, false
+}
+
+func (m *Manifest) SetChannel(ch Channel) {
+	for i := range m.Channels {
+		if m.Channels[i].ID == ch.ID {
+			m.Channels[i] = ch
+			return
+		}
+	}
+	m.Channels = append(m.Channels, ch)
+}
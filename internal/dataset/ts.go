@@ -0,0 +1,44 @@
+package dataset
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 슬랙 ts는 "1674000000.000100" 형태라 float로 바꾸면 정밀도를 잃기에 정수부와 소수부를 나눠 문자열로 비교함
+// oldest 파라미터처럼 소수부가 없는 ts와도 비교할 수 있음
+func TSLess(a, b string) bool {
+	ai, af, _ := strings.Cut(a, ".")
+	bi, bf, _ := strings.Cut(b, ".")
+	if len(ai) != len(bi) {
+		return len(ai) < len(bi)
+	}
+	if ai != bi {
+		return ai < bi
+	}
+	return af < bf
+}
+
+// 슬랙 ts를 시각으로 바꿈
+func ParseTS(ts string) (time.Time, error) {
+	sec, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid ts '%s'", ts)
+	}
+	var usec int64
+	if frac != "" {
+		if usec, err = strconv.ParseInt(frac, 10, 64); err != nil {
+			return time.Time{}, errors.Wrapf(err, "invalid ts '%s'", ts)
+		}
+	}
+	return time.Unix(s, usec*int64(time.Microsecond)), nil
+}
+
+// ts가 기간 안에 있는지
+func (w Window) Contains(ts string) bool {
+	return !TSLess(ts, w.OldestTS()) && !TSLess(w.LatestTS(), ts)
+}
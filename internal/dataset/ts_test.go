@@ -0,0 +1,38 @@
+package dataset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSLess(t *testing.T) {
+	assert.True(t, TSLess("1674000000.000100", "1674000000.000200"))
+	assert.True(t, TSLess("999999999.000100", "1674000000.000100"))
+	assert.True(t, TSLess("1674000000", "1674000000.000100"))
+	assert.True(t, TSLess("", "1674000000.000100"))
+	assert.False(t, TSLess("1674000001", "1674000000.999999"))
+}
+
+func TestParseTS(t *testing.T) {
+	at, err := ParseTS("1674000000.000100")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1674000000, 100*int64(time.Microsecond)), at)
+
+	at, err = ParseTS("1674000000")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1674000000, 0), at)
+
+	_, err = ParseTS("not a ts")
+	assert.Error(t, err)
+}
+
+func TestWindow_Contains(t *testing.T) {
+	w := Window{Oldest: time.Unix(1674000000, 0), Latest: time.Unix(1675000000, 0)}
+	assert.True(t, w.Contains("1674000000.000100"))
+	assert.True(t, w.Contains("1675000000"))
+	assert.False(t, w.Contains("1673999999.999999"))
+	assert.False(t, w.Contains("1675000000.000100"))
+}
@@ -0,0 +1,130 @@
+// Package emojiusage는 메시지의 텍스트, 리액션, 첨부, 블록에서 쓴 이모지를 뽑아 셈
+//
+// 이름은 모두 mrkdwn.NormalizeName으로 정규화하고 피부색은 빼서 같은 이모지는 한 이름으로 셈
+package emojiusage
+
+import (
+	"github.com/slack-go/slack"
+
+	"emojicleaner/internal/dataset"
+	"emojicleaner/internal/mrkdwn"
+)
+
+// 이모지가 메시지의 어디에서 쓰였는지
+const (
+	Text       = "text"
+	Reaction   = "reaction"
+	Attachment = "attachment"
+	Block      = "block"
+)
+
+// 코드, 링크, 멘션, 시각 안의 콜론은 이모지로 보지 않음. 피부색은 빼고 셈
+func FromText(s string) []string {
+	names := mrkdwn.Emojis(s)
+	if len(names) == 0 {
+		return nil
+	}
+
+	emojis := make([]string, 0, len(names))
+	for _, name := range names {
+		emojis = append(emojis, mrkdwn.NormalizeName(name))
+	}
+	return emojis
+}
+
+// 봇이 보내는 알림은 첨부의 제목, 본문, 필드, 꼬리말에 이모지를 넣는 경우가 많음
+func FromAttachments(aa []slack.Attachment) []string {
+	emojis := make([]string, 0)
+	for _, text := range AttachmentTexts(aa) {
+		emojis = append(emojis, FromText(text)...)
+	}
+	return emojis
+}
+
+// 첨부에서 이모지를 쓸 수 있는 텍스트
+func AttachmentTexts(aa []slack.Attachment) []string {
+	texts := make([]string, 0)
+	for _, a := range aa {
+		texts = append(texts, a.Pretext, a.Title, a.Text, a.Footer)
+		for _, f := range a.Fields {
+			texts = append(texts, f.Title, f.Value)
+		}
+	}
+	return texts
+}
+
+// 리액션 이모지 → 리액션을 단 사람 수
+func FromReactions(rr []slack.ItemReaction) map[string]int {
+	emojiCount := make(map[string]int)
+	for _, r := range rr {
+		emojiCount[RemoveSkinTone(mrkdwn.NormalizeName(r.Name))] += r.Count
+	}
+	return emojiCount
+}
+
+// "+1::skin-tone-3" to "+1"
+func RemoveSkinTone(s string) string {
+	name, _ := mrkdwn.SplitSkinTone(s)
+	return name
+}
+
+// 메시지를 보낸 사람이 텍스트, 첨부, 블록에 쓴 이모지. 리액션은 단 사람이 따로 있어 뺌
+func FromMessage(m dataset.Message) []string {
+	emojis := FromText(m.Text)
+	emojis = append(emojis, FromAttachments(m.Attachments)...)
+	return append(emojis, extraBlockEmojis(m.BlockEmojis, emojis)...)
+}
+
+// 사람이 보낸 메시지는 rich_text 블록이 Text와 같은 내용이라 텍스트와 첨부에서 센 만큼은 빼고 남는 것만 반환
+func extraBlockEmojis(blockEmojis, counted []string) []string {
+	seen := CountNames(counted)
+	extra := make([]string, 0)
+	for _, name := range blockEmojis {
+		if seen[name] > 0 {
+			seen[name]--
+			continue
+		}
+		extra = append(extra, name)
+	}
+	return extra
+}
+
+// 출처 → 이모지 → 사용 횟수
+func BySource(m dataset.Message) map[string]map[string]int {
+	text := FromText(m.Text)
+	attachments := FromAttachments(m.Attachments)
+	return map[string]map[string]int{
+		Text:       CountNames(text),
+		Reaction:   FromReactions(m.Reactions),
+		Attachment: CountNames(attachments),
+		Block:      CountNames(extraBlockEmojis(m.BlockEmojis, append(text, attachments...))),
+	}
+}
+
+// 텍스트, 리액션, 첨부, 블록에서 쓴 이모지를 모두 합쳐 셈
+func Count(m dataset.Message) map[string]int {
+	emojiCount := make(map[string]int)
+	for _, counts := range BySource(m) {
+		for name, count := range counts {
+			emojiCount[name] += count
+		}
+	}
+	return emojiCount
+}
+
+func CountNames(names []string) map[string]int {
+	counter := make(map[string]int)
+	for _, name := range names {
+		counter[name]++
+	}
+	return counter
+}
+
+// 이모지 이름 → 사용 횟수
+type Counter map[string]int
+
+func (c Counter) Add(m dataset.Message) {
+	for name, count := range Count(m) {
+		c[name] += count
+	}
+}
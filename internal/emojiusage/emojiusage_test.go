@@ -0,0 +1,154 @@
+package emojiusage
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+
+	"emojicleaner/internal/dataset"
+)
+
+func TestFromText(t *testing.T) {
+	cases := []struct {
+		name     string
+		given    string
+		expected []string
+	}{
+		{
+			name:     "길어도 됨",
+			given:    `:alphabet-yellow-w: :icon-colored-category-account-book-online-shopping-large1:`,
+			expected: []string{"alphabet-yellow-w", "icon-colored-category-account-book-online-shopping-large1"},
+		},
+		{
+			name: "list와 헷갈리지 말기",
+			given: `- 첫번째: 이건 이거고
+- 두번째: 이건 :tada:고
+- 세번째: 이건 https://link 링크다`,
+			expected: []string{"tada"},
+		},
+		{
+			name: "3개가 나와야함",
+			given: `:google-docs:*<https://link|구글 독스>* :tada: <!subteam^S71TDG26A|@그룹태그>
+	어쩌구저쩌구 (:green_salad: 테크샐러드)`,
+			expected: []string{"google-docs", "tada", "green_salad"},
+		},
+		{
+			name:     "없어야함",
+			given:    "이모지가 아무것도 없는 텍스트",
+			expected: nil,
+		},
+		{
+			name:     "연달아 이모지",
+			given:    ":smile::heart::tada:",
+			expected: []string{"smile", "heart", "tada"},
+		},
+		{
+			name:     "시각은 이모지가 아님",
+			given:    "2020-02-01 00:00:00 부터 23:49: 까지",
+			expected: nil,
+		},
+		{
+			name:     "코드는 건너뜀",
+			given:    "`a:b:c` ```\nkey:value:1\n``` :ok:",
+			expected: []string{"ok"},
+		},
+		{
+			name:     "멘션과 링크는 건너뜀",
+			given:    "<@U123|name:x:> <https://link/:y:|제목:z:> https://link/:w: :wave:",
+			expected: []string{"wave"},
+		},
+		{
+			name:     "피부색은 뺌",
+			given:    ":pray::skin-tone-2:",
+			expected: []string{"pray"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := FromText(tc.given)
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	got := Count(dataset.Message{Message: slack.Message{
+		Msg: slack.Msg{
+			Text: ":+1: :+1: :1+: :-1:",
+			Reactions: []slack.ItemReaction{
+				{
+					Name:  "+1",
+					Count: 10,
+				},
+				{
+					Name:  "+1::skin-tone-3",
+					Count: 5,
+				},
+			},
+		},
+	}})
+	assert.Equal(t, map[string]int{
+		"+1": 17,
+		"1+": 1,
+		"-1": 1,
+	}, got)
+}
+
+func TestRemoveSkinTone(t *testing.T) {
+	assert.Equal(t, "+1", RemoveSkinTone("+1"))
+	assert.Equal(t, "+1", RemoveSkinTone("+1::skin-tone-3"))
+}
+
+func TestBySource(t *testing.T) {
+	m := dataset.Message{
+		Message: slack.Message{Msg: slack.Msg{
+			Text:      "배포 완료 :tada: :tada:",
+			Reactions: []slack.ItemReaction{{Name: "eyes", Count: 2}},
+			Attachments: []slack.Attachment{{
+				Pretext: ":memo:",
+				Title:   "릴리즈 :rocket:",
+				Fields:  []slack.AttachmentField{{Title: "결과", Value: ":white_check_mark:"}},
+				Footer:  ":robot_face:",
+			}},
+		}},
+		// rich_text 블록은 Text와 같은 내용이라 tada는 겹치고, 헤더 블록의 rocket은 첨부와 겹치고, party만 새로 셈
+		BlockEmojis: []string{"tada", "tada", "rocket", "party"},
+	}
+
+	got := BySource(m)
+
+	assert.Equal(t, map[string]map[string]int{
+		Text:       {"tada": 2},
+		Reaction:   {"eyes": 2},
+		Attachment: {"memo": 1, "rocket": 1, "white_check_mark": 1, "robot_face": 1},
+		Block:      {"party": 1},
+	}, got)
+}
+
+func TestFromMessage(t *testing.T) {
+	m := dataset.Message{
+		Message: slack.Message{Msg: slack.Msg{
+			Text:        ":tada: :Tada:",
+			Reactions:   []slack.ItemReaction{{Name: "eyes", Count: 2}},
+			Attachments: []slack.Attachment{{Title: ":rocket:"}},
+		}},
+		BlockEmojis: []string{"tada", "tada", "party"},
+	}
+
+	// 리액션은 빼고 블록은 텍스트와 겹치지 않는 것만
+	assert.Equal(t, []string{"tada", "tada", "rocket", "party"}, FromMessage(m))
+}
+
+func TestCounter(t *testing.T) {
+	c := make(Counter)
+	c.Add(dataset.Message{Message: slack.Message{Msg: slack.Msg{
+		Text:      ":tada:",
+		Reactions: []slack.ItemReaction{{Name: "tada::skin-tone-2", Count: 2}},
+	}}})
+	c.Add(dataset.Message{Message: slack.Message{Msg: slack.Msg{Text: ":eyes:"}}})
+
+	assert.Equal(t, Counter{"tada": 3, "eyes": 1}, c)
+}
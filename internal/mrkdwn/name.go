@@ -0,0 +1,42 @@
+package mrkdwn
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 슬랙이 받아주는 이모지 이름의 최대 길이
+const maxNameLength = 100
+
+// 슬랙 커스텀 이모지 이름에 쓸 수 있는 글자
+// 모든 문자의 글자, 결합 기호(일본어 탁점 등), 숫자와 _, +, -, ', .
+func IsNameRune(r rune) bool {
+	switch {
+	case unicode.IsLetter(r), unicode.IsMark(r), unicode.IsNumber(r):
+		return true
+	case r == '_' || r == '+' || r == '-' || r == '\'' || r == '.':
+		return true
+	}
+	return false
+}
+
+// 슬랙이 이모지 이름으로 받아주는지
+func ValidName(name string) bool {
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return false
+	}
+	for _, r := range name {
+		if !IsNameRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// 슬랙처럼 대소문자를 구분하지 않고, 전각 문자나 맥에서 입력한 풀어쓴 한글(NFD)도 같은 이름으로 봄
+func NormalizeName(name string) string {
+	return strings.ToLower(norm.NFKC.String(name))
+}
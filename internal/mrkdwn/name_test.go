@@ -0,0 +1,33 @@
+package mrkdwn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidName(t *testing.T) {
+	valid := []string{"tada", "+1", "party-parrot_2", "감사합니다", "ㅋㅋㅋ", "ありがとう", "がんばって", "谢谢", "don't", "v1.2", "café"}
+	for _, name := range valid {
+		assert.True(t, ValidName(name), name)
+	}
+	invalid := []string{"", "a b", "a:b", "a/b", "<a>", strings.Repeat("a", maxNameLength+1)}
+	for _, name := range invalid {
+		assert.False(t, ValidName(name), name)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"Tada":    "tada",
+		"ＴＡＤＡ":    "tada",
+		"감사":      "감사",
+		"감사":   "감사", // 맥에서 입력한 풀어쓴 한글
+		"がんばって": "がんばって",
+		"Don't":   "don't",
+	}
+	for given, expected := range tests {
+		assert.Equal(t, expected, NormalizeName(given), given)
+	}
+}
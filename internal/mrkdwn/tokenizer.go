@@ -0,0 +1,184 @@
+// Package mrkdwn은 슬랙 메시지 텍스트(mrkdwn)에서 이모지를 찾음
+//
+// 정규식 하나로는 "12:00:00" 같은 시각이나 코드, URL, 멘션 안의 콜론도 이모지로 잡혀서
+// 텍스트를 앞에서부터 읽으며 이모지가 나올 수 없는 부분은 건너뜀
+package mrkdwn
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	codeFence = "```"
+	// 슬랙은 skin-tone-2부터 skin-tone-6까지 씀
+	SkinTonePrefix = "skin-tone-"
+)
+
+// 텍스트에 쓰인 이모지. :wave::skin-tone-2:처럼 바로 뒤에 피부색이 붙어있다면 SkinTone은 "skin-tone-2"
+type Emoji struct {
+	Name     string
+	SkinTone string
+}
+
+// 텍스트에 쓰인 이모지 이름을 순서대로 반환함. 이름은 정규화하지 않고 피부색은 뺌
+func Emojis(text string) []string {
+	tokens := Tokens(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// 텍스트에 쓰인 이모지를 순서대로 반환함. 이모지 없이 따로 쓴 피부색은 버림
+// 아래 부분의 콜론은 이모지로 보지 않음
+//   - ```코드 블록```과 `인라인 코드`
+//   - <@U123|name>, <#C123>, <!here>, <https://link|제목> 처럼 꺾쇠로 감싼 멘션과 링크
+//   - 꺾쇠 없이 쓴 https://link
+//   - 12:00:00 같은 시각
+func Tokens(text string) []Emoji {
+	spans := scan(text)
+	if len(spans) == 0 {
+		return nil
+	}
+	emojis := make([]Emoji, 0, len(spans))
+	for _, s := range spans {
+		emojis = append(emojis, s.Emoji)
+	}
+	return emojis
+}
+
+// 이모지와 피부색을 지운 텍스트. 코드, 링크, 시각 안의 콜론은 그대로 둠
+func RemoveEmojis(text string) string {
+	var b strings.Builder
+	var last int
+	for _, s := range scan(text) {
+		b.WriteString(text[last:s.start])
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// 텍스트 안에서 이모지가 차지하는 범위. 붙어있는 피부색까지 포함함
+type span struct {
+	Emoji
+	start, end int
+}
+
+func scan(text string) []span {
+	var spans []span
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], codeFence):
+			if end := strings.Index(text[i+len(codeFence):], codeFence); end >= 0 {
+				i += len(codeFence) + end + len(codeFence)
+				continue
+			}
+		case text[i] == '`':
+			// 인라인 코드는 줄을 넘지 않음
+			if end := strings.IndexAny(text[i+1:], "`\n"); end >= 0 && text[i+1+end] == '`' {
+				i += 1 + end + 1
+				continue
+			}
+		case text[i] == '<':
+			// 슬랙은 글자 그대로의 <를 &lt;로 바꿔 보내기에 <는 항상 멘션이나 링크의 시작
+			if end := strings.IndexByte(text[i:], '>'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		case isURLStart(text, i):
+			i += urlLength(text[i:])
+			continue
+		case text[i] == ':':
+			if name, ok := emojiAt(text, i); ok {
+				start := i
+				i += len(name) + 2
+				if isTime(text, start, name) {
+					continue
+				}
+				if strings.HasPrefix(name, SkinTonePrefix) {
+					// 이모지 바로 뒤에 붙어있어야 그 이모지의 피부색
+					if n := len(spans); n > 0 && spans[n-1].end == start && spans[n-1].SkinTone == "" {
+						spans[n-1].SkinTone = name
+						spans[n-1].end = i
+					}
+					continue
+				}
+				spans = append(spans, span{Emoji: Emoji{Name: name}, start: start, end: i})
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return spans
+}
+
+// 리액션 이름 "+1::skin-tone-3"을 "+1"과 "skin-tone-3"으로 나눔
+func SplitSkinTone(name string) (string, string) {
+	base, tone, ok := strings.Cut(name, "::")
+	if !ok || !strings.HasPrefix(tone, SkinTonePrefix) {
+		return base, ""
+	}
+	return base, tone
+}
+
+// text[i]의 콜론부터 다음 콜론까지가 이모지 이름이라면 이름을 반환
+func emojiAt(text string, i int) (string, bool) {
+	rest := text[i+1:]
+	end := strings.IndexByte(rest, ':')
+	if end <= 0 {
+		return "", false
+	}
+	name := rest[:end]
+	// "보기:https://link"의 :https:는 이모지가 아님
+	if strings.HasPrefix(rest[end+1:], "//") {
+		return "", false
+	}
+	if !ValidName(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// 숫자 바로 뒤에 온 숫자만으로 된 이름은 "12:00:00"이나 "10:30:"의 일부로 봄
+func isTime(text string, i int, name string) bool {
+	if i == 0 || !isDigit(text[i-1]) {
+		return false
+	}
+	for j := 0; j < len(name); j++ {
+		if !isDigit(name[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// 단어 중간이 아닌 곳에서 시작하는 http://, https://
+func isURLStart(text string, i int) bool {
+	if i > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:i])
+		if IsNameRune(r) {
+			return false
+		}
+	}
+	rest := text[i:]
+	return strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://")
+}
+
+// URL은 공백이나 꺾쇠 전까지로 봄
+func urlLength(s string) int {
+	if end := strings.IndexAny(s, " \t\n<>"); end >= 0 {
+		return end
+	}
+	return len(s)
+}
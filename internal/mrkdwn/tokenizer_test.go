@@ -0,0 +1,118 @@
+package mrkdwn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmojis(t *testing.T) {
+	cases := []struct {
+		name     string
+		given    string
+		expected []string
+	}{
+		{name: "이모지", given: "배포 완료 :tada:", expected: []string{"tada"}},
+		{name: "연달아 이모지", given: ":smile::heart::tada:", expected: []string{"smile", "heart", "tada"}},
+		{name: "한글과 기호", given: ":감사합니다: :+1: :thumbs-up_2:", expected: []string{"감사합니다", "+1", "thumbs-up_2"}},
+		{name: "다른 문자", given: ":ありがとう: :谢谢: :ㅋㅋ:", expected: []string{"ありがとう", "谢谢", "ㅋㅋ"}},
+		{name: "따옴표와 점", given: ":don't: :v1.2:", expected: []string{"don't", "v1.2"}},
+		{name: "피부색은 뺌", given: ":pray::skin-tone-2:", expected: []string{"pray"}},
+		{name: "시각", given: "2020-02-01 12:00:00에 배포 :rocket:", expected: []string{"rocket"}},
+		{name: "시와 분", given: "10:30: 회의, 23:49:", expected: nil},
+		{name: "숫자 이모지", given: "진짜 :100: 점", expected: []string{"100"}},
+		{name: "인라인 코드", given: "`a:b:c` 는 코드 :ok:", expected: []string{"ok"}},
+		{name: "닫히지 않은 인라인 코드", given: "`:tada:", expected: []string{"tada"}},
+		{name: "인라인 코드는 줄을 넘지 않음", given: "`\n:tada: `", expected: []string{"tada"}},
+		{name: "코드 블록", given: "```\nmap[string]int{\"a:b:c\": 1}\n:tada:\n```\n:done:", expected: []string{"done"}},
+		{name: "닫히지 않은 코드 블록", given: "```:tada:", expected: []string{"tada"}},
+		{name: "멘션", given: "<@U123|name:smile:> <!subteam^S71TDG26A|@그룹:tag:> :wave:", expected: []string{"wave"}},
+		{name: "링크", given: "<https://example.com/a:b:c|제목 :x:> :link:", expected: []string{"link"}},
+		{name: "URL", given: "https://example.com/:path:/a :eyes:", expected: []string{"eyes"}},
+		{name: "URL과 붙은 이모지", given: "보기:https://a.b/:c: 끝", expected: nil},
+		{name: "닫히지 않은 꺾쇠", given: "< :tada:", expected: []string{"tada"}},
+		{name: "없음", given: "이모지가 아무것도 없는 텍스트", expected: nil},
+		{name: "빈 이름", given: ":: :", expected: nil},
+		{name: "목록", given: "- 첫번째: 이건 이거고\n- 두번째: 이건 :tada:고", expected: []string{"tada"}},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Emojis(tc.given))
+		})
+	}
+}
+
+func TestTokens(t *testing.T) {
+	cases := []struct {
+		name     string
+		given    string
+		expected []Emoji
+	}{
+		{name: "피부색", given: ":wave::skin-tone-2: :wave:", expected: []Emoji{{Name: "wave", SkinTone: "skin-tone-2"}, {Name: "wave"}}},
+		{name: "떨어진 피부색은 버림", given: ":wave: :skin-tone-2: :skin-tone-3:", expected: []Emoji{{Name: "wave"}}},
+		{name: "피부색이 두 번", given: ":+1::skin-tone-3::skin-tone-4:", expected: []Emoji{{Name: "+1", SkinTone: "skin-tone-3"}}},
+		{name: "시각 뒤 피부색", given: "12:00::skin-tone-2:", expected: nil},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Tokens(tc.given))
+		})
+	}
+}
+
+func TestRemoveEmojis(t *testing.T) {
+	tests := map[string]string{
+		"배포 :tada: 완료":                "배포  완료",
+		":wave::skin-tone-2:안녕":       "안녕",
+		"12:00:00 `:code:` <@U1|:a:>": "12:00:00 `:code:` <@U1|:a:>",
+		"이모지 없음":                      "이모지 없음",
+	}
+	for given, expected := range tests {
+		assert.Equal(t, expected, RemoveEmojis(given), given)
+	}
+}
+
+func TestSplitSkinTone(t *testing.T) {
+	tests := map[string][2]string{
+		"+1::skin-tone-3": {"+1", "skin-tone-3"},
+		"+1":              {"+1", ""},
+		"a::b":            {"a", ""},
+	}
+	for given, expected := range tests {
+		base, tone := SplitSkinTone(given)
+		assert.Equal(t, expected, [2]string{base, tone}, given)
+	}
+}
+
+func FuzzEmojis(f *testing.F) {
+	for _, seed := range []string{
+		":smile::heart:",
+		"12:00:00 :tada:",
+		"```:a:``` `:b:` <@U1|:c:> https://d/:e:",
+		":pray::skin-tone-2:",
+		":한글:",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		for _, name := range Emojis(text) {
+			if name == "" {
+				t.Fatalf("empty name in %q", text)
+			}
+			if !strings.Contains(text, ":"+name+":") {
+				t.Fatalf("%q is not in %q", name, text)
+			}
+			if strings.HasPrefix(name, SkinTonePrefix) {
+				t.Fatalf("skin tone %q is returned from %q", name, text)
+			}
+			for _, r := range name {
+				if !IsNameRune(r) {
+					t.Fatalf("invalid rune %q in %q", r, name)
+				}
+			}
+		}
+	})
+}
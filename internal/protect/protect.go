@@ -0,0 +1,106 @@
+// Package protect는 stale, clean, duplicate가 같이 읽는 보호 목록을 다룸
+package protect
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"emojicleaner/internal/mrkdwn"
+)
+
+// 쓰이지 않거나 계획에 들어있더라도 지우면 안 되는 이모지 목록
+//
+//	{
+//	  "names": ["company-logo"],
+//	  "prefixes": ["alphabet-"],
+//	  "globs": ["team-*"],
+//	  "regexes": ["^flag-[a-z]{2}$"],
+//	  "aliases_of": ["ceo"]
+//	}
+type Rules struct {
+	Names    []string `json:"names"`
+	Prefixes []string `json:"prefixes"`
+	Globs    []string `json:"globs"`
+	Regexes  []string `json:"regexes"`
+	// 이 이모지와 이 이모지의 alias를 모두 보호
+	AliasesOf []string `json:"aliases_of"`
+
+	names     map[string]bool
+	aliasesOf map[string]bool
+	regexes   []*regexp.Regexp
+}
+
+// path가 비어있으면 아무것도 보호하지 않음
+func Load(path string) (*Rules, error) {
+	p := &Rules{}
+	if path != "" {
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bb, p); err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+	}
+	if err := p.Compile(); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return p, nil
+}
+
+// 규칙을 미리 컴파일해두고 잘못된 규칙이 있다면 에러를 반환
+func (p *Rules) Compile() error {
+	p.names = make(map[string]bool, len(p.Names))
+	for _, name := range p.Names {
+		p.names[mrkdwn.NormalizeName(name)] = true
+	}
+	p.aliasesOf = make(map[string]bool, len(p.AliasesOf))
+	for _, name := range p.AliasesOf {
+		p.aliasesOf[mrkdwn.NormalizeName(name)] = true
+	}
+	for _, glob := range p.Globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return errors.Wrapf(err, "glob '%s'", glob)
+		}
+	}
+	p.regexes = make([]*regexp.Regexp, 0, len(p.Regexes))
+	for _, expr := range p.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return errors.Wrapf(err, "regex '%s'", expr)
+		}
+		p.regexes = append(p.regexes, re)
+	}
+	return nil
+}
+
+// 보호하는 이모지라면 어떤 규칙 때문인지 반환. target은 alias라면 원본 이름, 아니라면 자기 자신
+func (p *Rules) Match(name, target string) (string, bool) {
+	if p.names[name] {
+		return "name:" + name, true
+	}
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(name, mrkdwn.NormalizeName(prefix)) {
+			return "prefix:" + prefix, true
+		}
+	}
+	for _, glob := range p.Globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return "glob:" + glob, true
+		}
+	}
+	for _, re := range p.regexes {
+		if re.MatchString(name) {
+			return "regex:" + re.String(), true
+		}
+	}
+	if p.aliasesOf[target] {
+		return "aliases_of:" + target, true
+	}
+	return "", false
+}
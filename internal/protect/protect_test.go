@@ -0,0 +1,66 @@
+package protect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules_Match(t *testing.T) {
+	p := &Rules{
+		Names:     []string{"company-logo"},
+		Prefixes:  []string{"alphabet-"},
+		Globs:     []string{"team-*"},
+		Regexes:   []string{"^flag-[a-z]{2}$"},
+		AliasesOf: []string{"ceo"},
+	}
+	require.NoError(t, p.Compile())
+
+	// 이름 → alias라면 원본 이름
+	targets := map[string]string{"boss": "ceo", "party2": "party"}
+	cases := map[string]string{
+		"company-logo":     "name:company-logo",
+		"alphabet-white-a": "prefix:alphabet-",
+		"team-dev":         "glob:team-*",
+		"flag-kr":          "regex:^flag-[a-z]{2}$",
+		"ceo":              "aliases_of:ceo",
+		"boss":             "aliases_of:ceo",
+		"flag-korea":       "",
+		"party2":           "",
+	}
+	for name, expected := range cases {
+		target, ok := targets[name]
+		if !ok {
+			target = name
+		}
+		got, ok := p.Match(name, target)
+		assert.Equal(t, expected != "", ok, name)
+		assert.Equal(t, expected, got, name)
+	}
+}
+
+func TestRules_Compile(t *testing.T) {
+	assert.Error(t, (&Rules{Regexes: []string{"("}}).Compile())
+	assert.Error(t, (&Rules{Globs: []string{"["}}).Compile())
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load("")
+	require.NoError(t, err)
+	_, ok := p.Match("party", "party")
+	assert.False(t, ok)
+
+	path := filepath.Join(t.TempDir(), "protect.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"names": ["keep"]}`), 0644))
+	p, err = Load(path)
+	require.NoError(t, err)
+	_, ok = p.Match("keep", "keep")
+	assert.True(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"regexes": ["("]}`), 0644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
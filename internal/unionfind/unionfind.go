@@ -0,0 +1,42 @@
+// Package unionfind는 stale의 비슷한 이름과 duplicate의 비슷한 이미지처럼 전이되는 관계로 원소를 묶음
+package unionfind
+
+// 원소 i의 부모. 루트는 자기 자신을 가리킴
+type UnionFind []int
+
+func New(n int) UnionFind {
+	u := make(UnionFind, n)
+	for i := range u {
+		u[i] = i
+	}
+	return u
+}
+
+func (u UnionFind) Find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+func (u UnionFind) Union(i, j int) {
+	u[u.Find(i)] = u.Find(j)
+}
+
+// 같은 묶음의 원소끼리 모음. 묶음은 가장 작은 원소 순이고 묶음 안의 원소도 오름차순
+func (u UnionFind) Groups() [][]int {
+	index := make(map[int]int)
+	groups := make([][]int, 0)
+	for i := range u {
+		root := u.Find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
@@ -0,0 +1,18 @@
+package unionfind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnionFind(t *testing.T) {
+	u := New(6)
+	u.Union(0, 3)
+	u.Union(4, 3)
+	u.Union(1, 5)
+
+	assert.Equal(t, u.Find(0), u.Find(4))
+	assert.NotEqual(t, u.Find(0), u.Find(1))
+	assert.Equal(t, [][]int{{0, 3, 4}, {1, 5}, {2}}, u.Groups())
+}